@@ -26,6 +26,19 @@ type App struct {
 	CredentialSrv    *services.CredentialSrv
 	WebsocketSrv     *services.WebsocketSrv
 	FileTransferSrv  *services.FileTransferSrv
+	SecurityAuditSrv *services.SecurityAuditSrv
+	TraceSrv         *services.TraceSrv
+	SessionSrv       *services.SessionSrv
+	KnownHostsSrv    *services.KnownHostsSrv
+	TunnelSrv        *services.TunnelSrv
+	PortSrv          *services.PortSrv
+	VaultSrv         *services.VaultSrv
+	SecretSrv        *services.SecretSrv
+	RotationSrv      *services.RotationSrv
+	AuditLogSrv      *services.AuditLogSrv
+	TemplateSrv      *services.TemplateSrv
+	HistorySrv       *services.HistorySrv
+	TrashSrv         *services.TrashSrv
 }
 
 func (a *App) Startup(ctx context.Context) {
@@ -35,7 +48,12 @@ func (a *App) Startup(ctx context.Context) {
 		log.SetLogLevel(logger.DEBUG)
 	}
 
+	a.VaultSrv.Load()
+	a.HistorySrv.Prune()
+	a.TrashSrv.Purge()
 	http.Handle("/ws/terminal", http.HandlerFunc(a.WebsocketSrv.TerminalHandle))
+
+	go a.TunnelSrv.AutoStart()
 }
 
 func (a *App) Bind() (bd []any) {
@@ -46,13 +64,36 @@ func (a *App) Bind() (bd []any) {
 	bd = append(bd, a.MetadataSrv)
 	bd = append(bd, a.CredentialSrv)
 	bd = append(bd, a.FileTransferSrv)
+	bd = append(bd, a.SecurityAuditSrv)
+	bd = append(bd, a.TraceSrv)
+	bd = append(bd, a.SessionSrv)
+	bd = append(bd, a.KnownHostsSrv)
+	bd = append(bd, a.TunnelSrv)
+	bd = append(bd, a.PortSrv)
+	bd = append(bd, a.VaultSrv)
+	bd = append(bd, a.SecretSrv)
+	bd = append(bd, a.RotationSrv)
+	bd = append(bd, a.AuditLogSrv)
+	bd = append(bd, a.TemplateSrv)
+	bd = append(bd, a.HistorySrv)
+	bd = append(bd, a.TrashSrv)
 	return
 }
 
 func (a *App) Enums() (es []any) {
 	es = append(es, enums.AuthMethodEnums)
+	es = append(es, enums.CredentialSourceEnums)
 	es = append(es, enums.ConnProtocolEnums)
 	es = append(es, enums.TerminalTypeEnums)
 	es = append(es, enums.FileTransferTaskStateEnums)
+	es = append(es, enums.AuditRatingEnums)
+	es = append(es, enums.TunnelTypeEnums)
+	es = append(es, enums.TunnelStatusEnums)
+	es = append(es, enums.RotationStatusEnums)
+	es = append(es, enums.AuditActionEnums)
+	es = append(es, enums.ExportFormatEnums)
+	es = append(es, enums.GroupDeleteModeEnums)
+	es = append(es, enums.SettingSourceEnums)
+	es = append(es, enums.TrashKindEnums)
 	return
 }
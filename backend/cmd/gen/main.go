@@ -13,6 +13,14 @@ func models() []any {
 		model.Credential{},
 		model.Group{},
 		model.Metadata{},
+		model.SecurityAudit{},
+		model.Tunnel{},
+		model.Vault{},
+		model.AuditLog{},
+		model.ConnectionTemplate{},
+		model.SessionHistory{},
+		model.Preferences{},
+		model.TrashItem{},
 	}
 }
 
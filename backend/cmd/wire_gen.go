@@ -18,39 +18,119 @@ func NewApp() *App {
 	httpListenerPort := initialize.InitHTTPServer()
 	logger := initialize.ProvideLogger(appContext)
 	query := initialize.InitDatabase()
-	connectionSrv := &services.ConnectionSrv{
+	secretSrv := &services.SecretSrv{
 		Logger: logger,
-		Query:  query,
+	}
+	auditLogSrv := &services.AuditLogSrv{
+		Logger:     logger,
+		Query:      query,
+		AppContext: appContext,
+	}
+	tunnelRunners := &services.TunnelRunners{}
+	connectionSrv := &services.ConnectionSrv{
+		Logger:        logger,
+		Query:         query,
+		SecretSrv:     secretSrv,
+		AuditLogSrv:   auditLogSrv,
+		TunnelRunners: tunnelRunners,
 	}
 	metadataSrv := &services.MetadataSrv{
+		Logger:        logger,
+		Query:         query,
+		ConnectionSrv: connectionSrv,
+	}
+	traceSrv := &services.TraceSrv{
+		Logger:     logger,
+		AppContext: appContext,
+	}
+	sessionSrv := &services.SessionSrv{
+		Logger: logger,
+	}
+	preferencesSrv := &services.PreferencesSrv{
 		Logger: logger,
 		Query:  query,
 	}
+	historySrv := &services.HistorySrv{
+		Logger:         logger,
+		Query:          query,
+		PreferencesSrv: preferencesSrv,
+	}
 	terminalSrv := &services.TerminalSrv{
 		Logger:           logger,
 		ConnectionSrv:    connectionSrv,
 		MetadataSrv:      metadataSrv,
+		TraceSrv:         traceSrv,
+		SessionSrv:       sessionSrv,
+		AuditLogSrv:      auditLogSrv,
+		HistorySrv:       historySrv,
 		HTTPListenerPort: httpListenerPort,
 	}
-	preferencesSrv := &services.PreferencesSrv{
-		Logger: logger,
-	}
 	groupSrv := &services.GroupSrv{
-		Logger: logger,
-		Query:  query,
+		Logger:        logger,
+		Query:         query,
+		TunnelRunners: tunnelRunners,
 	}
 	credentialSrv := &services.CredentialSrv{
-		Logger: logger,
-		Query:  query,
+		Logger:      logger,
+		Query:       query,
+		AuditLogSrv: auditLogSrv,
 	}
 	websocketSrv := &services.WebsocketSrv{
 		TerminalSrv: terminalSrv,
+		HistorySrv:  historySrv,
 		Logger:      logger,
 	}
 	fileTransferSrv := &services.FileTransferSrv{
 		Logger:        logger,
 		ConnectionSrv: connectionSrv,
 		AppContext:    appContext,
+		AuditLogSrv:   auditLogSrv,
+	}
+	securityAuditSrv := &services.SecurityAuditSrv{
+		Logger:        logger,
+		Query:         query,
+		ConnectionSrv: connectionSrv,
+		AppContext:    appContext,
+	}
+	knownHostsSrv := &services.KnownHostsSrv{
+		Logger:        logger,
+		Query:         query,
+		ConnectionSrv: connectionSrv,
+		AuditLogSrv:   auditLogSrv,
+	}
+	tunnelSrv := &services.TunnelSrv{
+		Logger:        logger,
+		Query:         query,
+		ConnectionSrv: connectionSrv,
+		AppContext:    appContext,
+		AuditLogSrv:   auditLogSrv,
+		Runners:       tunnelRunners,
+	}
+	portSrv := &services.PortSrv{
+		Logger:        logger,
+		ConnectionSrv: connectionSrv,
+		TunnelSrv:     tunnelSrv,
+	}
+	vaultSrv := &services.VaultSrv{
+		Logger: logger,
+		Query:  query,
+	}
+	rotationSrv := &services.RotationSrv{
+		Logger:        logger,
+		Query:         query,
+		ConnectionSrv: connectionSrv,
+		CredentialSrv: credentialSrv,
+		AuditLogSrv:   auditLogSrv,
+	}
+	templateSrv := &services.TemplateSrv{
+		Logger:      logger,
+		Query:       query,
+		AuditLogSrv: auditLogSrv,
+	}
+	trashSrv := &services.TrashSrv{
+		Logger:         logger,
+		Query:          query,
+		PreferencesSrv: preferencesSrv,
 	}
 	app := &App{
 		AppContext:       appContext,
@@ -64,6 +144,19 @@ func NewApp() *App {
 		CredentialSrv:    credentialSrv,
 		WebsocketSrv:     websocketSrv,
 		FileTransferSrv:  fileTransferSrv,
+		SecurityAuditSrv: securityAuditSrv,
+		TraceSrv:         traceSrv,
+		SessionSrv:       sessionSrv,
+		KnownHostsSrv:    knownHostsSrv,
+		TunnelSrv:        tunnelSrv,
+		PortSrv:          portSrv,
+		VaultSrv:         vaultSrv,
+		SecretSrv:        secretSrv,
+		RotationSrv:      rotationSrv,
+		AuditLogSrv:      auditLogSrv,
+		TemplateSrv:      templateSrv,
+		HistorySrv:       historySrv,
+		TrashSrv:         trashSrv,
 	}
 	return app
 }
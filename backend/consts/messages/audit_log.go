@@ -0,0 +1,6 @@
+package messages
+
+const (
+	AuditLogExportSuccess = "audit_log.export.success"
+	AuditLogUserCanceled  = "audit_log.user_canceled"
+)
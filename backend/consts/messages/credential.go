@@ -0,0 +1,5 @@
+package messages
+
+const (
+	CredentialInUse = "credential.error.in_use"
+)
@@ -0,0 +1,10 @@
+package messages
+
+const (
+	KnownHostRemoveSuccess = "known_hosts.remove.success"
+	KnownHostRepinSuccess  = "known_hosts.repin.success"
+	KnownHostScanSuccess   = "known_hosts.scan.success"
+	KnownHostTrustSuccess  = "known_hosts.trust.success"
+	HostKeyUnknown         = "known_hosts.confirm.unknown_host"
+	HostKeyChanged         = "known_hosts.confirm.host_key_changed"
+)
@@ -27,8 +27,49 @@ var CodeMapping = map[string]string{
 	FailedToParseFingerprint:   "Failed to parse fingerprint confirmation",
 	FailedToAddFingerprint:     "Failed to add host fingerprint",
 	UserRejectedFingerprint:    "User rejected host fingerprint",
+	FailedToReplaceFingerprint: "Failed to replace host fingerprint",
+	UserRejectedHostKeyChange:  "User rejected changed host key",
 
 	UploadSuccess:       "Upload successful",
 	DownloadSuccess:     "Download successful",
 	CreateFolderSuccess: "Folder creation successful",
+
+	AuditSuccess:       "Security audit completed",
+	AuditExportSuccess: "Security audit exported",
+
+	TraceExportSuccess: "Trace exported",
+
+	KnownHostRemoveSuccess: "Known host removed",
+	KnownHostRepinSuccess:  "Host key re-pinned",
+	KnownHostScanSuccess:   "Host key scan completed",
+	KnownHostTrustSuccess:  "Host key trusted",
+	HostKeyUnknown:         "Host key must be confirmed before connecting",
+	HostKeyChanged:         "Host key has changed and must be confirmed before connecting",
+
+	TunnelStartSuccess: "Tunnel started",
+	TunnelStopSuccess:  "Tunnel stopped",
+	TunnelRunning:      "Stop the tunnel before editing it",
+
+	VaultLocked:                 "Vault is locked, unlock it with the master password",
+	VaultNotEnabled:             "Master password is not set",
+	VaultAlreadyEnabled:         "Master password is already set",
+	InvalidMasterPassword:       "Invalid master password",
+	MasterPasswordTooShort:      "Master password must be at least 8 characters",
+	VaultEnableSuccess:          "Master password enabled",
+	VaultDisableSuccess:         "Master password disabled",
+	VaultUnlockSuccess:          "Vault unlocked",
+	VaultLockSuccess:            "Vault locked",
+	MasterPasswordChangeSuccess: "Master password changed",
+
+	SecretCommandSuccess: "Secret command succeeded",
+	SecretCacheCleared:   "Secret cache cleared",
+
+	CredentialInUse: "Credential is still used by connections or groups",
+
+	RotationFinished: "Password rotation finished",
+
+	AuditLogExportSuccess: "Audit log exported",
+
+	TrashRestoreSuccess: "Restored from trash",
+	TrashPurgeSuccess:   "Trash emptied",
 }
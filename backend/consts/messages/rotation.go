@@ -0,0 +1,5 @@
+package messages
+
+const (
+	RotationFinished = "rotation.finished"
+)
@@ -0,0 +1,6 @@
+package messages
+
+const (
+	SecretCommandSuccess = "secret.command.success"
+	SecretCacheCleared   = "secret.cache.cleared"
+)
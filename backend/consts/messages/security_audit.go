@@ -0,0 +1,7 @@
+package messages
+
+const (
+	AuditSuccess       = "security_audit.audit.success"
+	AuditExportSuccess = "security_audit.export.success"
+	AuditUserCanceled  = "security_audit.user_canceled"
+)
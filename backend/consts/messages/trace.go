@@ -0,0 +1,6 @@
+package messages
+
+const (
+	TraceExportSuccess = "trace.export.success"
+	TraceUserCanceled  = "trace.user_canceled"
+)
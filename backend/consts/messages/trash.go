@@ -0,0 +1,6 @@
+package messages
+
+const (
+	TrashRestoreSuccess = "trash.restore.success"
+	TrashPurgeSuccess   = "trash.purge.success"
+)
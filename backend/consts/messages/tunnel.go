@@ -0,0 +1,7 @@
+package messages
+
+const (
+	TunnelStartSuccess = "tunnel.start.success"
+	TunnelStopSuccess  = "tunnel.stop.success"
+	TunnelRunning      = "tunnel.running"
+)
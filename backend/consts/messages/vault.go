@@ -0,0 +1,14 @@
+package messages
+
+const (
+	VaultLocked                 = "vault.error.locked"
+	VaultNotEnabled             = "vault.error.not_enabled"
+	VaultAlreadyEnabled         = "vault.error.already_enabled"
+	InvalidMasterPassword       = "vault.error.invalid_master_password"
+	MasterPasswordTooShort      = "vault.error.master_password_too_short"
+	VaultEnableSuccess          = "vault.enable.success"
+	VaultDisableSuccess         = "vault.disable.success"
+	VaultUnlockSuccess          = "vault.unlock.success"
+	VaultLockSuccess            = "vault.lock.success"
+	MasterPasswordChangeSuccess = "vault.change_password.success"
+)
@@ -21,4 +21,6 @@ const (
 	FailedToParseFingerprint   = "websocket.error.failed_to_parse_fingerprint"
 	FailedToAddFingerprint     = "websocket.error.failed_to_add_fingerprint"
 	UserRejectedFingerprint    = "websocket.info.user_rejected_fingerprint"
+	FailedToReplaceFingerprint = "websocket.error.failed_to_replace_fingerprint"
+	UserRejectedHostKeyChange  = "websocket.info.user_rejected_host_key_change"
 )
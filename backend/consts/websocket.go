@@ -2,4 +2,7 @@ package consts
 
 import "time"
 
-const WebSocketWriteWait = 10 * time.Second
+const (
+	WebSocketWriteWait   = 10 * time.Second
+	SessionStatsInterval = 5 * time.Second
+)
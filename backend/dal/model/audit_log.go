@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"gorm.io/gorm"
+)
+
+var ErrAuditLogAppendOnly = errors.New("audit log is append-only")
+
+// AuditLog records a security relevant action. Records are never updated or
+// deleted, so the model has no UpdatedAt or DeletedAt.
+type AuditLog struct {
+	ID           uint              `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time         `json:"createdAt" gorm:"index"`
+	Action       enums.AuditAction `json:"action" gorm:"not null;index"`
+	ConnectionID uint              `json:"connectionId" gorm:"index"`
+	CredentialID uint              `json:"credentialId"`
+	// Target is the host, file path or tunnel address the action applies to.
+	Target string `json:"target"`
+	Detail string `json:"detail"`
+	Size   int64  `json:"size"`
+}
+
+func (a *AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
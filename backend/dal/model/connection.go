@@ -1,33 +1,50 @@
 package model
 
 import (
+	"time"
+
 	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/types"
 	"go.bug.st/serial"
 )
 
 type Connection struct {
 	Common
-	Label                  string             `gorm:"uniqueIndex;not null" json:"label"`
-	Host                   string             `json:"host"`
-	Port                   uint               `json:"port"`
-	SerialPort             string             `json:"serialPort"`
-	ConnProtocol           enums.ConnProtocol `gorm:"not null" json:"connProtocol"`
-	CredentialID           *uint              `gorm:"not null" json:"credentialID"`
-	Credential             *Credential        `json:"credential"`
-	UseCommonCredential    bool               `gorm:"not null" json:"useCommonCredential"`
-	Metadata               *Metadata          `json:"metadata"`
-	GroupID                *uint              `json:"groupID"`
-	BaudRate               int                `json:"baudRate"`
-	DataBits               int                `json:"dataBits"`
-	StopBits               serial.StopBits    `json:"stopBits"`
-	Parity                 serial.Parity      `json:"parity"`
-	Theme                  string             `json:"theme" gorm:"not null,default:'Default'"`
-	SSHKeyExchanges        []string           `json:"sshKeyExchanges" gorm:"type:json;serializer:json"`
-	SSHCiphers             []string           `json:"sshCiphers" gorm:"type:json;serializer:json"`
-	SSHMACs                []string           `json:"sshMACs" gorm:"type:json;serializer:json"`
-	SSHPublicKeyAlgorithms []string           `json:"sshPublicKeyAlgorithms" gorm:"type:json;serializer:json"`
-	SSHHostKeyAlgorithms   []string           `json:"sshHostKeyAlgorithms" gorm:"type:json;serializer:json"`
-	SSHCharset             string             `json:"sshCharset" gorm:"default:'UTF-8'"`
+	Label                 string             `gorm:"uniqueIndex:idx_connections_live_label,where:deleted_at IS NULL;not null" json:"label"`
+	Host                  string             `json:"host"`
+	SerialPort            string             `json:"serialPort"`
+	ConnProtocol          enums.ConnProtocol `gorm:"not null" json:"connProtocol"`
+	CredentialID          *uint              `json:"credentialID"`
+	Credential            *Credential        `json:"credential"`
+	UseCommonCredential   bool               `gorm:"not null" json:"useCommonCredential"`
+	Metadata              *Metadata          `json:"metadata"`
+	SecurityAudit         *SecurityAudit     `json:"securityAudit"`
+	GroupID               *uint              `json:"groupID"`
+	SortOrder             int                `json:"sortOrder"`
+	BaudRate              int                `json:"baudRate"`
+	DataBits              int                `json:"dataBits"`
+	StopBits              serial.StopBits    `json:"stopBits"`
+	Parity                serial.Parity      `json:"parity"`
+	FallbackCredentialIDs []uint             `json:"fallbackCredentialIDs" gorm:"type:json;serializer:json"`
+	TryDefaultIdentities  bool               `json:"tryDefaultIdentities"`
+	MaxAuthTries          int                `json:"maxAuthTries"`
+	LastAuthIdentity      string             `json:"lastAuthIdentity"`
+	DebugTrace            bool               `json:"debugTrace"`
+	Tags                  []string           `json:"tags" gorm:"type:json;serializer:json"`
+	Attributes            map[string]string  `json:"attributes" gorm:"type:json;serializer:json"`
+	ConnectionSettings
+	// Overrides lists the JSON names of the settings set on the connection itself,
+	// with credentialID for a pinned common credential. Every other setting is
+	// inherited, whatever value it holds. Connections saved before overrides were
+	// recorded have none, their non-empty settings are the overridden ones.
+	Overrides []string `json:"overrides" gorm:"type:json;serializer:json"`
+	// SettingOrigins tells where each effective setting came from, keyed by its JSON
+	// name. It is only filled by ConnectionSrv.FindByID.
+	SettingOrigins map[string]*types.SettingOrigin `json:"settingOrigins,omitempty" gorm:"-"`
+	// LastConnectedAt and ConnectCount come from the session history. They are only
+	// filled by ListConnection and HistorySrv.RecentConnections.
+	LastConnectedAt *time.Time `json:"lastConnectedAt,omitempty" gorm:"-"`
+	ConnectCount    int64      `json:"connectCount" gorm:"-"`
 }
 
 func (c *Connection) TableName() string {
@@ -0,0 +1,28 @@
+package model
+
+import "github.com/Q191/GTerm/backend/enums"
+
+// ConnectionTemplate is a saved blueprint that connections are stamped out from, one
+// per host. A private credential is copied into every stamped connection.
+type ConnectionTemplate struct {
+	Common
+	Name string `json:"name" gorm:"uniqueIndex;not null"`
+	// LabelPattern names the stamped connections, see TemplateSrv.StampTemplate.
+	LabelPattern          string             `json:"labelPattern"`
+	ConnProtocol          enums.ConnProtocol `json:"connProtocol" gorm:"not null"`
+	CredentialID          *uint              `json:"credentialID"`
+	Credential            *Credential        `json:"credential"`
+	UseCommonCredential   bool               `json:"useCommonCredential"`
+	GroupID               *uint              `json:"groupID"`
+	FallbackCredentialIDs []uint             `json:"fallbackCredentialIDs" gorm:"type:json;serializer:json"`
+	TryDefaultIdentities  bool               `json:"tryDefaultIdentities"`
+	MaxAuthTries          int                `json:"maxAuthTries"`
+	DebugTrace            bool               `json:"debugTrace"`
+	Tags                  []string           `json:"tags" gorm:"type:json;serializer:json"`
+	Attributes            map[string]string  `json:"attributes" gorm:"type:json;serializer:json"`
+	ConnectionSettings
+}
+
+func (t *ConnectionTemplate) TableName() string {
+	return "connection_templates"
+}
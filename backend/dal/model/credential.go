@@ -8,7 +8,7 @@ import (
 
 type Credential struct {
 	Common
-	Label                string           `json:"label" gorm:"uniqueIndex;not null"`
+	Label                string           `json:"label" gorm:"uniqueIndex:idx_credentials_live_label,where:deleted_at IS NULL;not null"`
 	Username             string           `json:"username"`
 	IsCommonCredential   bool             `json:"isCommonCredential"`
 	AuthMethod           enums.AuthMethod `json:"authMethod"`
@@ -21,6 +21,13 @@ type Credential struct {
 	Passphrase           string `json:"passphrase" gorm:"-"`
 	PassphraseCiphertext string
 	PassphraseSalt       string
+	// Source Command resolves the secrets below at connect time through an external
+	// password manager; SecretCacheTTL is in seconds, 0 uses the default.
+	Source            enums.CredentialSource `json:"source"`
+	PasswordCommand   string                 `json:"passwordCommand"`
+	PrivateKeyCommand string                 `json:"privateKeyCommand"`
+	PassphraseCommand string                 `json:"passphraseCommand"`
+	SecretCacheTTL    int                    `json:"secretCacheTTL"`
 }
 
 func (c *Credential) TableName() string {
@@ -28,6 +35,14 @@ func (c *Credential) TableName() string {
 }
 
 func (c *Credential) BeforeSave(tx *gorm.DB) error {
+	if c.Source == enums.CredentialSourceCommand {
+		// secrets from external providers never reach the database
+		c.Password, c.PrivateKey, c.Passphrase = "", "", ""
+		c.PasswordCiphertext, c.PasswordSalt = "", ""
+		c.PrivateKeyCiphertext, c.PrivateKeySalt = "", ""
+		c.PassphraseCiphertext, c.PassphraseSalt = "", ""
+		return nil
+	}
 	cred, err := encrypt.NewCredential()
 	if err != nil {
 		return err
@@ -2,7 +2,19 @@ package model
 
 type Group struct {
 	Common
-	Name string `json:"name" gorm:"uniqueIndex;not null"`
+	Name      string `json:"name" gorm:"uniqueIndex:idx_groups_live_parent_name,where:deleted_at IS NULL;not null"`
+	ParentID  *uint  `json:"parentID" gorm:"uniqueIndex:idx_groups_live_parent_name,where:deleted_at IS NULL"`
+	SortOrder int    `json:"sortOrder"`
+	// Rule makes this a smart group, whose connections are the ones matching the
+	// search query at the time of use. Smart groups hold no connections, subgroups or
+	// defaults of their own.
+	Rule                  string `json:"rule"`
+	FallbackCredentialIDs []uint `json:"fallbackCredentialIDs" gorm:"type:json;serializer:json"`
+	TryDefaultIdentities  bool   `json:"tryDefaultIdentities"`
+	// CredentialID is the common credential used by connections without their own.
+	CredentialID *uint `json:"credentialID"`
+	ConnectionSettings
+	Children []*Group `json:"children" gorm:"-"`
 }
 
 func (g *Group) TableName() string {
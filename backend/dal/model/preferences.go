@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Preferences holds the application settings in a single row.
+type Preferences struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// HistoryRetentionDays and HistoryLimit bound the session history, HistoryLimit
+	// per connection. Zero keeps everything.
+	HistoryRetentionDays int `json:"historyRetentionDays"`
+	HistoryLimit         int `json:"historyLimit"`
+	// TrashRetentionDays is how long deleted items can be restored, zero keeps them
+	// until the trash is emptied.
+	TrashRetentionDays int `json:"trashRetentionDays"`
+}
+
+func (p *Preferences) TableName() string {
+	return "preferences"
+}
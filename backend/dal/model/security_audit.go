@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/types"
+)
+
+type SecurityAudit struct {
+	Common
+	ConnectionID  uint                         `json:"connectionId" gorm:"not null"`
+	ServerVersion string                       `json:"serverVersion"`
+	Offered       *types.SSHAlgorithms         `json:"offered" gorm:"type:json;serializer:json"`
+	Expected      *types.SSHExpectedAlgorithms `json:"expected" gorm:"type:json;serializer:json"`
+	Findings      []*types.SSHAuditFinding     `json:"findings" gorm:"type:json;serializer:json"`
+	Rating        enums.AuditRating            `json:"rating"`
+}
+
+func (a *SecurityAudit) TableName() string {
+	return "security_audits"
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+)
+
+// SessionHistory is one terminal session, from the connection attempt until the
+// websocket closed. Label and Host are copied so the history stays readable after
+// the connection changes or is deleted.
+type SessionHistory struct {
+	ID           uint               `json:"id" gorm:"primaryKey"`
+	ConnectionID uint               `json:"connectionId" gorm:"index"`
+	Label        string             `json:"label"`
+	Host         string             `json:"host"`
+	Protocol     enums.ConnProtocol `json:"protocol"`
+	StartedAt    time.Time          `json:"startedAt" gorm:"index"`
+	// ConnectedAt is nil when the session failed before the terminal was ready.
+	ConnectedAt *time.Time `json:"connectedAt"`
+	EndedAt     *time.Time `json:"endedAt"`
+	// Duration is the time in seconds the session was connected.
+	Duration int64 `json:"duration"`
+	// ExitReason is the message code the websocket was closed with.
+	ExitReason string `json:"exitReason"`
+}
+
+func (h *SessionHistory) TableName() string {
+	return "session_histories"
+}
@@ -0,0 +1,24 @@
+package model
+
+// ConnectionSettings are shared by connections and groups. A group leaves a setting
+// empty to inherit it from its parent; a connection inherits every setting missing
+// from its Overrides from the nearest group that sets it.
+type ConnectionSettings struct {
+	Port                   uint     `json:"port"`
+	Theme                  string   `json:"theme"`
+	SSHKeyExchanges        []string `json:"sshKeyExchanges" gorm:"type:json;serializer:json"`
+	SSHCiphers             []string `json:"sshCiphers" gorm:"type:json;serializer:json"`
+	SSHMACs                []string `json:"sshMACs" gorm:"type:json;serializer:json"`
+	SSHPublicKeyAlgorithms []string `json:"sshPublicKeyAlgorithms" gorm:"type:json;serializer:json"`
+	SSHHostKeyAlgorithms   []string `json:"sshHostKeyAlgorithms" gorm:"type:json;serializer:json"`
+	SSHCharset             string   `json:"sshCharset"`
+	// JumpConnectionID is the saved connection used as jump host.
+	JumpConnectionID *uint `json:"jumpConnectionID"`
+	// Proxy is a socks5://, socks5h:// or http:// URL without user:password.
+	Proxy string `json:"proxy"`
+	// ProxyCredentialID is the common credential whose username and password
+	// authenticate with the proxy.
+	ProxyCredentialID *uint `json:"proxyCredentialID"`
+	// KeepAliveInterval is in seconds.
+	KeepAliveInterval int `json:"keepAliveInterval"`
+}
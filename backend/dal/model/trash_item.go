@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+)
+
+// TrashItem is one deletion that can be undone. The rows it covers stay soft
+// deleted until the item is restored or purged, the ID lists name every one of them
+// so a connection comes back together with its private credential and metadata.
+type TrashItem struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time       `json:"createdAt" gorm:"index"`
+	Kind      enums.TrashKind `json:"kind" gorm:"not null"`
+	// Name is the label or name of the deleted connection, credential or group.
+	Name             string `json:"name"`
+	ConnectionIDs    []uint `json:"connectionIds" gorm:"type:json;serializer:json"`
+	CredentialIDs    []uint `json:"credentialIds" gorm:"type:json;serializer:json"`
+	GroupIDs         []uint `json:"groupIds" gorm:"type:json;serializer:json"`
+	MetadataIDs      []uint `json:"-" gorm:"type:json;serializer:json"`
+	SecurityAuditIDs []uint `json:"-" gorm:"type:json;serializer:json"`
+	TunnelIDs        []uint `json:"-" gorm:"type:json;serializer:json"`
+}
+
+func (t *TrashItem) TableName() string {
+	return "trash_items"
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/utils/encrypt"
+	"gorm.io/gorm"
+)
+
+type Tunnel struct {
+	Common
+	Name         string           `json:"name" gorm:"not null"`
+	ConnectionID uint             `json:"connectionId" gorm:"not null;index"`
+	Type         enums.TunnelType `json:"type" gorm:"not null"`
+	Bind         string           `json:"bind" gorm:"not null"`
+	Target       string           `json:"target"`
+	RemoteDNS    bool             `json:"remoteDNS"`
+	AutoStart    bool             `json:"autoStart"`
+	// Username and Password protect the SOCKS listener of dynamic tunnels.
+	Username           string `json:"username"`
+	Password           string `json:"password" gorm:"-"`
+	PasswordCiphertext string
+	PasswordSalt       string
+}
+
+func (t *Tunnel) TableName() string {
+	return "tunnels"
+}
+
+func (t *Tunnel) BeforeSave(tx *gorm.DB) error {
+	if t.Password == "" {
+		return nil
+	}
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return err
+	}
+	encrypted, err := cred.EncryptPassword(t.Password)
+	if err != nil {
+		return err
+	}
+	t.PasswordCiphertext, t.PasswordSalt = encrypted.Ciphertext, encrypted.Salt
+	return nil
+}
+
+func (t *Tunnel) Decrypt() error {
+	if t.PasswordCiphertext == "" || t.PasswordSalt == "" {
+		return nil
+	}
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return err
+	}
+	t.Password, err = cred.DecryptPassword(t.PasswordCiphertext, t.PasswordSalt)
+	return err
+}
@@ -0,0 +1,16 @@
+package model
+
+// Vault holds the master password protected data key. Credentials are encrypted
+// with the machine ID derived key while no vault row exists.
+type Vault struct {
+	Common
+	Salt       string `json:"-" gorm:"not null"`
+	Time       uint32 `json:"-"`
+	Memory     uint32 `json:"-"`
+	Threads    uint8  `json:"-"`
+	WrappedKey string `json:"-" gorm:"not null"`
+}
+
+func (v *Vault) TableName() string {
+	return "vaults"
+}
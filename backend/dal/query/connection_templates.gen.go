@@ -0,0 +1,566 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+)
+
+func newConnectionTemplate(db *gorm.DB, opts ...gen.DOOption) connectionTemplate {
+	_connectionTemplate := connectionTemplate{}
+
+	_connectionTemplate.connectionTemplateDo.UseDB(db, opts...)
+	_connectionTemplate.connectionTemplateDo.UseModel(&model.ConnectionTemplate{})
+
+	tableName := _connectionTemplate.connectionTemplateDo.TableName()
+	_connectionTemplate.ALL = field.NewAsterisk(tableName)
+	_connectionTemplate.ID = field.NewUint(tableName, "id")
+	_connectionTemplate.CreatedAt = field.NewTime(tableName, "created_at")
+	_connectionTemplate.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_connectionTemplate.DeletedAt = field.NewField(tableName, "deleted_at")
+	_connectionTemplate.Name = field.NewString(tableName, "name")
+	_connectionTemplate.LabelPattern = field.NewString(tableName, "label_pattern")
+	_connectionTemplate.ConnProtocol = field.NewString(tableName, "conn_protocol")
+	_connectionTemplate.CredentialID = field.NewUint(tableName, "credential_id")
+	_connectionTemplate.UseCommonCredential = field.NewBool(tableName, "use_common_credential")
+	_connectionTemplate.GroupID = field.NewUint(tableName, "group_id")
+	_connectionTemplate.FallbackCredentialIDs = field.NewField(tableName, "fallback_credential_ids")
+	_connectionTemplate.TryDefaultIdentities = field.NewBool(tableName, "try_default_identities")
+	_connectionTemplate.MaxAuthTries = field.NewInt(tableName, "max_auth_tries")
+	_connectionTemplate.DebugTrace = field.NewBool(tableName, "debug_trace")
+	_connectionTemplate.Tags = field.NewField(tableName, "tags")
+	_connectionTemplate.Attributes = field.NewField(tableName, "attributes")
+	_connectionTemplate.Port = field.NewUint(tableName, "port")
+	_connectionTemplate.Theme = field.NewString(tableName, "theme")
+	_connectionTemplate.SSHKeyExchanges = field.NewField(tableName, "ssh_key_exchanges")
+	_connectionTemplate.SSHCiphers = field.NewField(tableName, "ssh_ciphers")
+	_connectionTemplate.SSHMACs = field.NewField(tableName, "ssh_ma_cs")
+	_connectionTemplate.SSHPublicKeyAlgorithms = field.NewField(tableName, "ssh_public_key_algorithms")
+	_connectionTemplate.SSHHostKeyAlgorithms = field.NewField(tableName, "ssh_host_key_algorithms")
+	_connectionTemplate.SSHCharset = field.NewString(tableName, "ssh_charset")
+	_connectionTemplate.JumpConnectionID = field.NewUint(tableName, "jump_connection_id")
+	_connectionTemplate.Proxy = field.NewString(tableName, "proxy")
+	_connectionTemplate.ProxyCredentialID = field.NewUint(tableName, "proxy_credential_id")
+	_connectionTemplate.KeepAliveInterval = field.NewInt(tableName, "keep_alive_interval")
+	_connectionTemplate.Credential = connectionTemplateBelongsToCredential{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("Credential", "model.Credential"),
+	}
+
+	_connectionTemplate.fillFieldMap()
+
+	return _connectionTemplate
+}
+
+type connectionTemplate struct {
+	connectionTemplateDo
+
+	ALL                    field.Asterisk
+	ID                     field.Uint
+	CreatedAt              field.Time
+	UpdatedAt              field.Time
+	DeletedAt              field.Field
+	Name                   field.String
+	LabelPattern           field.String
+	ConnProtocol           field.String
+	CredentialID           field.Uint
+	UseCommonCredential    field.Bool
+	GroupID                field.Uint
+	FallbackCredentialIDs  field.Field
+	TryDefaultIdentities   field.Bool
+	MaxAuthTries           field.Int
+	DebugTrace             field.Bool
+	Tags                   field.Field
+	Attributes             field.Field
+	Port                   field.Uint
+	Theme                  field.String
+	SSHKeyExchanges        field.Field
+	SSHCiphers             field.Field
+	SSHMACs                field.Field
+	SSHPublicKeyAlgorithms field.Field
+	SSHHostKeyAlgorithms   field.Field
+	SSHCharset             field.String
+	JumpConnectionID       field.Uint
+	Proxy                  field.String
+	ProxyCredentialID      field.Uint
+	KeepAliveInterval      field.Int
+	Credential             connectionTemplateBelongsToCredential
+
+	fieldMap map[string]field.Expr
+}
+
+func (c connectionTemplate) Table(newTableName string) *connectionTemplate {
+	c.connectionTemplateDo.UseTable(newTableName)
+	return c.updateTableName(newTableName)
+}
+
+func (c connectionTemplate) As(alias string) *connectionTemplate {
+	c.connectionTemplateDo.DO = *(c.connectionTemplateDo.As(alias).(*gen.DO))
+	return c.updateTableName(alias)
+}
+
+func (c *connectionTemplate) updateTableName(table string) *connectionTemplate {
+	c.ALL = field.NewAsterisk(table)
+	c.ID = field.NewUint(table, "id")
+	c.CreatedAt = field.NewTime(table, "created_at")
+	c.UpdatedAt = field.NewTime(table, "updated_at")
+	c.DeletedAt = field.NewField(table, "deleted_at")
+	c.Name = field.NewString(table, "name")
+	c.LabelPattern = field.NewString(table, "label_pattern")
+	c.ConnProtocol = field.NewString(table, "conn_protocol")
+	c.CredentialID = field.NewUint(table, "credential_id")
+	c.UseCommonCredential = field.NewBool(table, "use_common_credential")
+	c.GroupID = field.NewUint(table, "group_id")
+	c.FallbackCredentialIDs = field.NewField(table, "fallback_credential_ids")
+	c.TryDefaultIdentities = field.NewBool(table, "try_default_identities")
+	c.MaxAuthTries = field.NewInt(table, "max_auth_tries")
+	c.DebugTrace = field.NewBool(table, "debug_trace")
+	c.Tags = field.NewField(table, "tags")
+	c.Attributes = field.NewField(table, "attributes")
+	c.Port = field.NewUint(table, "port")
+	c.Theme = field.NewString(table, "theme")
+	c.SSHKeyExchanges = field.NewField(table, "ssh_key_exchanges")
+	c.SSHCiphers = field.NewField(table, "ssh_ciphers")
+	c.SSHMACs = field.NewField(table, "ssh_ma_cs")
+	c.SSHPublicKeyAlgorithms = field.NewField(table, "ssh_public_key_algorithms")
+	c.SSHHostKeyAlgorithms = field.NewField(table, "ssh_host_key_algorithms")
+	c.SSHCharset = field.NewString(table, "ssh_charset")
+	c.JumpConnectionID = field.NewUint(table, "jump_connection_id")
+	c.Proxy = field.NewString(table, "proxy")
+	c.ProxyCredentialID = field.NewUint(table, "proxy_credential_id")
+	c.KeepAliveInterval = field.NewInt(table, "keep_alive_interval")
+
+	c.fillFieldMap()
+
+	return c
+}
+
+func (c *connectionTemplate) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := c.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (c *connectionTemplate) fillFieldMap() {
+	c.fieldMap = make(map[string]field.Expr, 29)
+	c.fieldMap["id"] = c.ID
+	c.fieldMap["created_at"] = c.CreatedAt
+	c.fieldMap["updated_at"] = c.UpdatedAt
+	c.fieldMap["deleted_at"] = c.DeletedAt
+	c.fieldMap["name"] = c.Name
+	c.fieldMap["label_pattern"] = c.LabelPattern
+	c.fieldMap["conn_protocol"] = c.ConnProtocol
+	c.fieldMap["credential_id"] = c.CredentialID
+	c.fieldMap["use_common_credential"] = c.UseCommonCredential
+	c.fieldMap["group_id"] = c.GroupID
+	c.fieldMap["fallback_credential_ids"] = c.FallbackCredentialIDs
+	c.fieldMap["try_default_identities"] = c.TryDefaultIdentities
+	c.fieldMap["max_auth_tries"] = c.MaxAuthTries
+	c.fieldMap["debug_trace"] = c.DebugTrace
+	c.fieldMap["tags"] = c.Tags
+	c.fieldMap["attributes"] = c.Attributes
+	c.fieldMap["port"] = c.Port
+	c.fieldMap["theme"] = c.Theme
+	c.fieldMap["ssh_key_exchanges"] = c.SSHKeyExchanges
+	c.fieldMap["ssh_ciphers"] = c.SSHCiphers
+	c.fieldMap["ssh_ma_cs"] = c.SSHMACs
+	c.fieldMap["ssh_public_key_algorithms"] = c.SSHPublicKeyAlgorithms
+	c.fieldMap["ssh_host_key_algorithms"] = c.SSHHostKeyAlgorithms
+	c.fieldMap["ssh_charset"] = c.SSHCharset
+	c.fieldMap["jump_connection_id"] = c.JumpConnectionID
+	c.fieldMap["proxy"] = c.Proxy
+	c.fieldMap["proxy_credential_id"] = c.ProxyCredentialID
+	c.fieldMap["keep_alive_interval"] = c.KeepAliveInterval
+
+}
+
+func (c connectionTemplate) clone(db *gorm.DB) connectionTemplate {
+	c.connectionTemplateDo.ReplaceConnPool(db.Statement.ConnPool)
+	return c
+}
+
+func (c connectionTemplate) replaceDB(db *gorm.DB) connectionTemplate {
+	c.connectionTemplateDo.ReplaceDB(db)
+	return c
+}
+
+type connectionTemplateBelongsToCredential struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a connectionTemplateBelongsToCredential) Where(conds ...field.Expr) *connectionTemplateBelongsToCredential {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a connectionTemplateBelongsToCredential) WithContext(ctx context.Context) *connectionTemplateBelongsToCredential {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a connectionTemplateBelongsToCredential) Session(session *gorm.Session) *connectionTemplateBelongsToCredential {
+	a.db = a.db.Session(session)
+	return &a
+}
+
+func (a connectionTemplateBelongsToCredential) Model(m *model.ConnectionTemplate) *connectionTemplateBelongsToCredentialTx {
+	return &connectionTemplateBelongsToCredentialTx{a.db.Model(m).Association(a.Name())}
+}
+
+type connectionTemplateBelongsToCredentialTx struct{ tx *gorm.Association }
+
+func (a connectionTemplateBelongsToCredentialTx) Find() (result *model.Credential, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a connectionTemplateBelongsToCredentialTx) Append(values ...*model.Credential) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a connectionTemplateBelongsToCredentialTx) Replace(values ...*model.Credential) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a connectionTemplateBelongsToCredentialTx) Delete(values ...*model.Credential) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a connectionTemplateBelongsToCredentialTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a connectionTemplateBelongsToCredentialTx) Count() int64 {
+	return a.tx.Count()
+}
+
+type connectionTemplateDo struct{ gen.DO }
+
+type IConnectionTemplateDo interface {
+	gen.SubQuery
+	Debug() IConnectionTemplateDo
+	WithContext(ctx context.Context) IConnectionTemplateDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IConnectionTemplateDo
+	WriteDB() IConnectionTemplateDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IConnectionTemplateDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IConnectionTemplateDo
+	Not(conds ...gen.Condition) IConnectionTemplateDo
+	Or(conds ...gen.Condition) IConnectionTemplateDo
+	Select(conds ...field.Expr) IConnectionTemplateDo
+	Where(conds ...gen.Condition) IConnectionTemplateDo
+	Order(conds ...field.Expr) IConnectionTemplateDo
+	Distinct(cols ...field.Expr) IConnectionTemplateDo
+	Omit(cols ...field.Expr) IConnectionTemplateDo
+	Join(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo
+	Group(cols ...field.Expr) IConnectionTemplateDo
+	Having(conds ...gen.Condition) IConnectionTemplateDo
+	Limit(limit int) IConnectionTemplateDo
+	Offset(offset int) IConnectionTemplateDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IConnectionTemplateDo
+	Unscoped() IConnectionTemplateDo
+	Create(values ...*model.ConnectionTemplate) error
+	CreateInBatches(values []*model.ConnectionTemplate, batchSize int) error
+	Save(values ...*model.ConnectionTemplate) error
+	First() (*model.ConnectionTemplate, error)
+	Take() (*model.ConnectionTemplate, error)
+	Last() (*model.ConnectionTemplate, error)
+	Find() ([]*model.ConnectionTemplate, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.ConnectionTemplate, err error)
+	FindInBatches(result *[]*model.ConnectionTemplate, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.ConnectionTemplate) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IConnectionTemplateDo
+	Assign(attrs ...field.AssignExpr) IConnectionTemplateDo
+	Joins(fields ...field.RelationField) IConnectionTemplateDo
+	Preload(fields ...field.RelationField) IConnectionTemplateDo
+	FirstOrInit() (*model.ConnectionTemplate, error)
+	FirstOrCreate() (*model.ConnectionTemplate, error)
+	FindByPage(offset int, limit int) (result []*model.ConnectionTemplate, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IConnectionTemplateDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (c connectionTemplateDo) Debug() IConnectionTemplateDo {
+	return c.withDO(c.DO.Debug())
+}
+
+func (c connectionTemplateDo) WithContext(ctx context.Context) IConnectionTemplateDo {
+	return c.withDO(c.DO.WithContext(ctx))
+}
+
+func (c connectionTemplateDo) ReadDB() IConnectionTemplateDo {
+	return c.Clauses(dbresolver.Read)
+}
+
+func (c connectionTemplateDo) WriteDB() IConnectionTemplateDo {
+	return c.Clauses(dbresolver.Write)
+}
+
+func (c connectionTemplateDo) Session(config *gorm.Session) IConnectionTemplateDo {
+	return c.withDO(c.DO.Session(config))
+}
+
+func (c connectionTemplateDo) Clauses(conds ...clause.Expression) IConnectionTemplateDo {
+	return c.withDO(c.DO.Clauses(conds...))
+}
+
+func (c connectionTemplateDo) Returning(value interface{}, columns ...string) IConnectionTemplateDo {
+	return c.withDO(c.DO.Returning(value, columns...))
+}
+
+func (c connectionTemplateDo) Not(conds ...gen.Condition) IConnectionTemplateDo {
+	return c.withDO(c.DO.Not(conds...))
+}
+
+func (c connectionTemplateDo) Or(conds ...gen.Condition) IConnectionTemplateDo {
+	return c.withDO(c.DO.Or(conds...))
+}
+
+func (c connectionTemplateDo) Select(conds ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Select(conds...))
+}
+
+func (c connectionTemplateDo) Where(conds ...gen.Condition) IConnectionTemplateDo {
+	return c.withDO(c.DO.Where(conds...))
+}
+
+func (c connectionTemplateDo) Order(conds ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Order(conds...))
+}
+
+func (c connectionTemplateDo) Distinct(cols ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Distinct(cols...))
+}
+
+func (c connectionTemplateDo) Omit(cols ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Omit(cols...))
+}
+
+func (c connectionTemplateDo) Join(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Join(table, on...))
+}
+
+func (c connectionTemplateDo) LeftJoin(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.LeftJoin(table, on...))
+}
+
+func (c connectionTemplateDo) RightJoin(table schema.Tabler, on ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.RightJoin(table, on...))
+}
+
+func (c connectionTemplateDo) Group(cols ...field.Expr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Group(cols...))
+}
+
+func (c connectionTemplateDo) Having(conds ...gen.Condition) IConnectionTemplateDo {
+	return c.withDO(c.DO.Having(conds...))
+}
+
+func (c connectionTemplateDo) Limit(limit int) IConnectionTemplateDo {
+	return c.withDO(c.DO.Limit(limit))
+}
+
+func (c connectionTemplateDo) Offset(offset int) IConnectionTemplateDo {
+	return c.withDO(c.DO.Offset(offset))
+}
+
+func (c connectionTemplateDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IConnectionTemplateDo {
+	return c.withDO(c.DO.Scopes(funcs...))
+}
+
+func (c connectionTemplateDo) Unscoped() IConnectionTemplateDo {
+	return c.withDO(c.DO.Unscoped())
+}
+
+func (c connectionTemplateDo) Create(values ...*model.ConnectionTemplate) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return c.DO.Create(values)
+}
+
+func (c connectionTemplateDo) CreateInBatches(values []*model.ConnectionTemplate, batchSize int) error {
+	return c.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (c connectionTemplateDo) Save(values ...*model.ConnectionTemplate) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return c.DO.Save(values)
+}
+
+func (c connectionTemplateDo) First() (*model.ConnectionTemplate, error) {
+	if result, err := c.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ConnectionTemplate), nil
+	}
+}
+
+func (c connectionTemplateDo) Take() (*model.ConnectionTemplate, error) {
+	if result, err := c.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ConnectionTemplate), nil
+	}
+}
+
+func (c connectionTemplateDo) Last() (*model.ConnectionTemplate, error) {
+	if result, err := c.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ConnectionTemplate), nil
+	}
+}
+
+func (c connectionTemplateDo) Find() ([]*model.ConnectionTemplate, error) {
+	result, err := c.DO.Find()
+	return result.([]*model.ConnectionTemplate), err
+}
+
+func (c connectionTemplateDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.ConnectionTemplate, err error) {
+	buf := make([]*model.ConnectionTemplate, 0, batchSize)
+	err = c.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (c connectionTemplateDo) FindInBatches(result *[]*model.ConnectionTemplate, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return c.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (c connectionTemplateDo) Attrs(attrs ...field.AssignExpr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Attrs(attrs...))
+}
+
+func (c connectionTemplateDo) Assign(attrs ...field.AssignExpr) IConnectionTemplateDo {
+	return c.withDO(c.DO.Assign(attrs...))
+}
+
+func (c connectionTemplateDo) Joins(fields ...field.RelationField) IConnectionTemplateDo {
+	for _, _f := range fields {
+		c = *c.withDO(c.DO.Joins(_f))
+	}
+	return &c
+}
+
+func (c connectionTemplateDo) Preload(fields ...field.RelationField) IConnectionTemplateDo {
+	for _, _f := range fields {
+		c = *c.withDO(c.DO.Preload(_f))
+	}
+	return &c
+}
+
+func (c connectionTemplateDo) FirstOrInit() (*model.ConnectionTemplate, error) {
+	if result, err := c.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ConnectionTemplate), nil
+	}
+}
+
+func (c connectionTemplateDo) FirstOrCreate() (*model.ConnectionTemplate, error) {
+	if result, err := c.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.ConnectionTemplate), nil
+	}
+}
+
+func (c connectionTemplateDo) FindByPage(offset int, limit int) (result []*model.ConnectionTemplate, count int64, err error) {
+	result, err = c.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = c.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (c connectionTemplateDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = c.Count()
+	if err != nil {
+		return
+	}
+
+	err = c.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (c connectionTemplateDo) Scan(result interface{}) (err error) {
+	return c.DO.Scan(result)
+}
+
+func (c connectionTemplateDo) Delete(models ...*model.ConnectionTemplate) (result gen.ResultInfo, err error) {
+	return c.DO.Delete(models)
+}
+
+func (c *connectionTemplateDo) withDO(do gen.Dao) *connectionTemplateDo {
+	c.DO = *do.(*gen.DO)
+	return c
+}
@@ -33,16 +33,24 @@ func newConnection(db *gorm.DB, opts ...gen.DOOption) connection {
 	_connection.DeletedAt = field.NewField(tableName, "deleted_at")
 	_connection.Label = field.NewString(tableName, "label")
 	_connection.Host = field.NewString(tableName, "host")
-	_connection.Port = field.NewUint(tableName, "port")
 	_connection.SerialPort = field.NewString(tableName, "serial_port")
 	_connection.ConnProtocol = field.NewString(tableName, "conn_protocol")
 	_connection.CredentialID = field.NewUint(tableName, "credential_id")
 	_connection.UseCommonCredential = field.NewBool(tableName, "use_common_credential")
 	_connection.GroupID = field.NewUint(tableName, "group_id")
+	_connection.SortOrder = field.NewInt(tableName, "sort_order")
 	_connection.BaudRate = field.NewInt(tableName, "baud_rate")
 	_connection.DataBits = field.NewInt(tableName, "data_bits")
 	_connection.StopBits = field.NewInt(tableName, "stop_bits")
 	_connection.Parity = field.NewInt(tableName, "parity")
+	_connection.FallbackCredentialIDs = field.NewField(tableName, "fallback_credential_ids")
+	_connection.TryDefaultIdentities = field.NewBool(tableName, "try_default_identities")
+	_connection.MaxAuthTries = field.NewInt(tableName, "max_auth_tries")
+	_connection.LastAuthIdentity = field.NewString(tableName, "last_auth_identity")
+	_connection.DebugTrace = field.NewBool(tableName, "debug_trace")
+	_connection.Tags = field.NewField(tableName, "tags")
+	_connection.Attributes = field.NewField(tableName, "attributes")
+	_connection.Port = field.NewUint(tableName, "port")
 	_connection.Theme = field.NewString(tableName, "theme")
 	_connection.SSHKeyExchanges = field.NewField(tableName, "ssh_key_exchanges")
 	_connection.SSHCiphers = field.NewField(tableName, "ssh_ciphers")
@@ -50,12 +58,23 @@ func newConnection(db *gorm.DB, opts ...gen.DOOption) connection {
 	_connection.SSHPublicKeyAlgorithms = field.NewField(tableName, "ssh_public_key_algorithms")
 	_connection.SSHHostKeyAlgorithms = field.NewField(tableName, "ssh_host_key_algorithms")
 	_connection.SSHCharset = field.NewString(tableName, "ssh_charset")
+	_connection.JumpConnectionID = field.NewUint(tableName, "jump_connection_id")
+	_connection.Proxy = field.NewString(tableName, "proxy")
+	_connection.ProxyCredentialID = field.NewUint(tableName, "proxy_credential_id")
+	_connection.KeepAliveInterval = field.NewInt(tableName, "keep_alive_interval")
+	_connection.Overrides = field.NewField(tableName, "overrides")
 	_connection.Metadata = connectionHasOneMetadata{
 		db: db.Session(&gorm.Session{}),
 
 		RelationField: field.NewRelation("Metadata", "model.Metadata"),
 	}
 
+	_connection.SecurityAudit = connectionHasOneSecurityAudit{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("SecurityAudit", "model.SecurityAudit"),
+	}
+
 	_connection.Credential = connectionBelongsToCredential{
 		db: db.Session(&gorm.Session{}),
 
@@ -77,16 +96,24 @@ type connection struct {
 	DeletedAt              field.Field
 	Label                  field.String
 	Host                   field.String
-	Port                   field.Uint
 	SerialPort             field.String
 	ConnProtocol           field.String
 	CredentialID           field.Uint
 	UseCommonCredential    field.Bool
 	GroupID                field.Uint
+	SortOrder              field.Int
 	BaudRate               field.Int
 	DataBits               field.Int
 	StopBits               field.Int
 	Parity                 field.Int
+	FallbackCredentialIDs  field.Field
+	TryDefaultIdentities   field.Bool
+	MaxAuthTries           field.Int
+	LastAuthIdentity       field.String
+	DebugTrace             field.Bool
+	Tags                   field.Field
+	Attributes             field.Field
+	Port                   field.Uint
 	Theme                  field.String
 	SSHKeyExchanges        field.Field
 	SSHCiphers             field.Field
@@ -94,8 +121,15 @@ type connection struct {
 	SSHPublicKeyAlgorithms field.Field
 	SSHHostKeyAlgorithms   field.Field
 	SSHCharset             field.String
+	JumpConnectionID       field.Uint
+	Proxy                  field.String
+	ProxyCredentialID      field.Uint
+	KeepAliveInterval      field.Int
+	Overrides              field.Field
 	Metadata               connectionHasOneMetadata
 
+	SecurityAudit connectionHasOneSecurityAudit
+
 	Credential connectionBelongsToCredential
 
 	fieldMap map[string]field.Expr
@@ -119,16 +153,24 @@ func (c *connection) updateTableName(table string) *connection {
 	c.DeletedAt = field.NewField(table, "deleted_at")
 	c.Label = field.NewString(table, "label")
 	c.Host = field.NewString(table, "host")
-	c.Port = field.NewUint(table, "port")
 	c.SerialPort = field.NewString(table, "serial_port")
 	c.ConnProtocol = field.NewString(table, "conn_protocol")
 	c.CredentialID = field.NewUint(table, "credential_id")
 	c.UseCommonCredential = field.NewBool(table, "use_common_credential")
 	c.GroupID = field.NewUint(table, "group_id")
+	c.SortOrder = field.NewInt(table, "sort_order")
 	c.BaudRate = field.NewInt(table, "baud_rate")
 	c.DataBits = field.NewInt(table, "data_bits")
 	c.StopBits = field.NewInt(table, "stop_bits")
 	c.Parity = field.NewInt(table, "parity")
+	c.FallbackCredentialIDs = field.NewField(table, "fallback_credential_ids")
+	c.TryDefaultIdentities = field.NewBool(table, "try_default_identities")
+	c.MaxAuthTries = field.NewInt(table, "max_auth_tries")
+	c.LastAuthIdentity = field.NewString(table, "last_auth_identity")
+	c.DebugTrace = field.NewBool(table, "debug_trace")
+	c.Tags = field.NewField(table, "tags")
+	c.Attributes = field.NewField(table, "attributes")
+	c.Port = field.NewUint(table, "port")
 	c.Theme = field.NewString(table, "theme")
 	c.SSHKeyExchanges = field.NewField(table, "ssh_key_exchanges")
 	c.SSHCiphers = field.NewField(table, "ssh_ciphers")
@@ -136,6 +178,11 @@ func (c *connection) updateTableName(table string) *connection {
 	c.SSHPublicKeyAlgorithms = field.NewField(table, "ssh_public_key_algorithms")
 	c.SSHHostKeyAlgorithms = field.NewField(table, "ssh_host_key_algorithms")
 	c.SSHCharset = field.NewString(table, "ssh_charset")
+	c.JumpConnectionID = field.NewUint(table, "jump_connection_id")
+	c.Proxy = field.NewString(table, "proxy")
+	c.ProxyCredentialID = field.NewUint(table, "proxy_credential_id")
+	c.KeepAliveInterval = field.NewInt(table, "keep_alive_interval")
+	c.Overrides = field.NewField(table, "overrides")
 
 	c.fillFieldMap()
 
@@ -152,23 +199,31 @@ func (c *connection) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (c *connection) fillFieldMap() {
-	c.fieldMap = make(map[string]field.Expr, 25)
+	c.fieldMap = make(map[string]field.Expr, 39)
 	c.fieldMap["id"] = c.ID
 	c.fieldMap["created_at"] = c.CreatedAt
 	c.fieldMap["updated_at"] = c.UpdatedAt
 	c.fieldMap["deleted_at"] = c.DeletedAt
 	c.fieldMap["label"] = c.Label
 	c.fieldMap["host"] = c.Host
-	c.fieldMap["port"] = c.Port
 	c.fieldMap["serial_port"] = c.SerialPort
 	c.fieldMap["conn_protocol"] = c.ConnProtocol
 	c.fieldMap["credential_id"] = c.CredentialID
 	c.fieldMap["use_common_credential"] = c.UseCommonCredential
 	c.fieldMap["group_id"] = c.GroupID
+	c.fieldMap["sort_order"] = c.SortOrder
 	c.fieldMap["baud_rate"] = c.BaudRate
 	c.fieldMap["data_bits"] = c.DataBits
 	c.fieldMap["stop_bits"] = c.StopBits
 	c.fieldMap["parity"] = c.Parity
+	c.fieldMap["fallback_credential_ids"] = c.FallbackCredentialIDs
+	c.fieldMap["try_default_identities"] = c.TryDefaultIdentities
+	c.fieldMap["max_auth_tries"] = c.MaxAuthTries
+	c.fieldMap["last_auth_identity"] = c.LastAuthIdentity
+	c.fieldMap["debug_trace"] = c.DebugTrace
+	c.fieldMap["tags"] = c.Tags
+	c.fieldMap["attributes"] = c.Attributes
+	c.fieldMap["port"] = c.Port
 	c.fieldMap["theme"] = c.Theme
 	c.fieldMap["ssh_key_exchanges"] = c.SSHKeyExchanges
 	c.fieldMap["ssh_ciphers"] = c.SSHCiphers
@@ -176,6 +231,11 @@ func (c *connection) fillFieldMap() {
 	c.fieldMap["ssh_public_key_algorithms"] = c.SSHPublicKeyAlgorithms
 	c.fieldMap["ssh_host_key_algorithms"] = c.SSHHostKeyAlgorithms
 	c.fieldMap["ssh_charset"] = c.SSHCharset
+	c.fieldMap["jump_connection_id"] = c.JumpConnectionID
+	c.fieldMap["proxy"] = c.Proxy
+	c.fieldMap["proxy_credential_id"] = c.ProxyCredentialID
+	c.fieldMap["keep_alive_interval"] = c.KeepAliveInterval
+	c.fieldMap["overrides"] = c.Overrides
 
 }
 
@@ -260,6 +320,77 @@ func (a connectionHasOneMetadataTx) Count() int64 {
 	return a.tx.Count()
 }
 
+type connectionHasOneSecurityAudit struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a connectionHasOneSecurityAudit) Where(conds ...field.Expr) *connectionHasOneSecurityAudit {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a connectionHasOneSecurityAudit) WithContext(ctx context.Context) *connectionHasOneSecurityAudit {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a connectionHasOneSecurityAudit) Session(session *gorm.Session) *connectionHasOneSecurityAudit {
+	a.db = a.db.Session(session)
+	return &a
+}
+
+func (a connectionHasOneSecurityAudit) Model(m *model.Connection) *connectionHasOneSecurityAuditTx {
+	return &connectionHasOneSecurityAuditTx{a.db.Model(m).Association(a.Name())}
+}
+
+type connectionHasOneSecurityAuditTx struct{ tx *gorm.Association }
+
+func (a connectionHasOneSecurityAuditTx) Find() (result *model.SecurityAudit, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a connectionHasOneSecurityAuditTx) Append(values ...*model.SecurityAudit) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a connectionHasOneSecurityAuditTx) Replace(values ...*model.SecurityAudit) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a connectionHasOneSecurityAuditTx) Delete(values ...*model.SecurityAudit) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a connectionHasOneSecurityAuditTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a connectionHasOneSecurityAuditTx) Count() int64 {
+	return a.tx.Count()
+}
+
 type connectionBelongsToCredential struct {
 	db *gorm.DB
 
@@ -41,6 +41,11 @@ func newCredential(db *gorm.DB, opts ...gen.DOOption) credential {
 	_credential.PrivateKeySalt = field.NewString(tableName, "private_key_salt")
 	_credential.PassphraseCiphertext = field.NewString(tableName, "passphrase_ciphertext")
 	_credential.PassphraseSalt = field.NewString(tableName, "passphrase_salt")
+	_credential.Source = field.NewString(tableName, "source")
+	_credential.PasswordCommand = field.NewString(tableName, "password_command")
+	_credential.PrivateKeyCommand = field.NewString(tableName, "private_key_command")
+	_credential.PassphraseCommand = field.NewString(tableName, "passphrase_command")
+	_credential.SecretCacheTTL = field.NewInt(tableName, "secret_cache_ttl")
 
 	_credential.fillFieldMap()
 
@@ -65,6 +70,11 @@ type credential struct {
 	PrivateKeySalt       field.String
 	PassphraseCiphertext field.String
 	PassphraseSalt       field.String
+	Source               field.String
+	PasswordCommand      field.String
+	PrivateKeyCommand    field.String
+	PassphraseCommand    field.String
+	SecretCacheTTL       field.Int
 
 	fieldMap map[string]field.Expr
 }
@@ -95,6 +105,11 @@ func (c *credential) updateTableName(table string) *credential {
 	c.PrivateKeySalt = field.NewString(table, "private_key_salt")
 	c.PassphraseCiphertext = field.NewString(table, "passphrase_ciphertext")
 	c.PassphraseSalt = field.NewString(table, "passphrase_salt")
+	c.Source = field.NewString(table, "source")
+	c.PasswordCommand = field.NewString(table, "password_command")
+	c.PrivateKeyCommand = field.NewString(table, "private_key_command")
+	c.PassphraseCommand = field.NewString(table, "passphrase_command")
+	c.SecretCacheTTL = field.NewInt(table, "secret_cache_ttl")
 
 	c.fillFieldMap()
 
@@ -111,7 +126,7 @@ func (c *credential) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (c *credential) fillFieldMap() {
-	c.fieldMap = make(map[string]field.Expr, 14)
+	c.fieldMap = make(map[string]field.Expr, 19)
 	c.fieldMap["id"] = c.ID
 	c.fieldMap["created_at"] = c.CreatedAt
 	c.fieldMap["updated_at"] = c.UpdatedAt
@@ -126,6 +141,11 @@ func (c *credential) fillFieldMap() {
 	c.fieldMap["private_key_salt"] = c.PrivateKeySalt
 	c.fieldMap["passphrase_ciphertext"] = c.PassphraseCiphertext
 	c.fieldMap["passphrase_salt"] = c.PassphraseSalt
+	c.fieldMap["source"] = c.Source
+	c.fieldMap["password_command"] = c.PasswordCommand
+	c.fieldMap["private_key_command"] = c.PrivateKeyCommand
+	c.fieldMap["passphrase_command"] = c.PassphraseCommand
+	c.fieldMap["secret_cache_ttl"] = c.SecretCacheTTL
 }
 
 func (c credential) clone(db *gorm.DB) credential {
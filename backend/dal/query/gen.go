@@ -16,49 +16,89 @@ import (
 )
 
 var (
-	Q          = new(Query)
-	Connection *connection
-	Credential *credential
-	Group      *group
-	Metadata   *metadata
+	Q                  = new(Query)
+	AuditLog           *auditLog
+	Connection         *connection
+	ConnectionTemplate *connectionTemplate
+	Credential         *credential
+	Group              *group
+	Metadata           *metadata
+	Preferences        *preferences
+	SecurityAudit      *securityAudit
+	SessionHistory     *sessionHistory
+	TrashItem          *trashItem
+	Tunnel             *tunnel
+	Vault              *vault
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
+	AuditLog = &Q.AuditLog
 	Connection = &Q.Connection
+	ConnectionTemplate = &Q.ConnectionTemplate
 	Credential = &Q.Credential
 	Group = &Q.Group
 	Metadata = &Q.Metadata
+	Preferences = &Q.Preferences
+	SecurityAudit = &Q.SecurityAudit
+	SessionHistory = &Q.SessionHistory
+	TrashItem = &Q.TrashItem
+	Tunnel = &Q.Tunnel
+	Vault = &Q.Vault
 }
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:         db,
-		Connection: newConnection(db, opts...),
-		Credential: newCredential(db, opts...),
-		Group:      newGroup(db, opts...),
-		Metadata:   newMetadata(db, opts...),
+		db:                 db,
+		AuditLog:           newAuditLog(db, opts...),
+		Connection:         newConnection(db, opts...),
+		ConnectionTemplate: newConnectionTemplate(db, opts...),
+		Credential:         newCredential(db, opts...),
+		Group:              newGroup(db, opts...),
+		Metadata:           newMetadata(db, opts...),
+		Preferences:        newPreferences(db, opts...),
+		SecurityAudit:      newSecurityAudit(db, opts...),
+		SessionHistory:     newSessionHistory(db, opts...),
+		TrashItem:          newTrashItem(db, opts...),
+		Tunnel:             newTunnel(db, opts...),
+		Vault:              newVault(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Connection connection
-	Credential credential
-	Group      group
-	Metadata   metadata
+	AuditLog           auditLog
+	Connection         connection
+	ConnectionTemplate connectionTemplate
+	Credential         credential
+	Group              group
+	Metadata           metadata
+	Preferences        preferences
+	SecurityAudit      securityAudit
+	SessionHistory     sessionHistory
+	TrashItem          trashItem
+	Tunnel             tunnel
+	Vault              vault
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:         db,
-		Connection: q.Connection.clone(db),
-		Credential: q.Credential.clone(db),
-		Group:      q.Group.clone(db),
-		Metadata:   q.Metadata.clone(db),
+		db:                 db,
+		AuditLog:           q.AuditLog.clone(db),
+		Connection:         q.Connection.clone(db),
+		ConnectionTemplate: q.ConnectionTemplate.clone(db),
+		Credential:         q.Credential.clone(db),
+		Group:              q.Group.clone(db),
+		Metadata:           q.Metadata.clone(db),
+		Preferences:        q.Preferences.clone(db),
+		SecurityAudit:      q.SecurityAudit.clone(db),
+		SessionHistory:     q.SessionHistory.clone(db),
+		TrashItem:          q.TrashItem.clone(db),
+		Tunnel:             q.Tunnel.clone(db),
+		Vault:              q.Vault.clone(db),
 	}
 }
 
@@ -72,27 +112,51 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:         db,
-		Connection: q.Connection.replaceDB(db),
-		Credential: q.Credential.replaceDB(db),
-		Group:      q.Group.replaceDB(db),
-		Metadata:   q.Metadata.replaceDB(db),
+		db:                 db,
+		AuditLog:           q.AuditLog.replaceDB(db),
+		Connection:         q.Connection.replaceDB(db),
+		ConnectionTemplate: q.ConnectionTemplate.replaceDB(db),
+		Credential:         q.Credential.replaceDB(db),
+		Group:              q.Group.replaceDB(db),
+		Metadata:           q.Metadata.replaceDB(db),
+		Preferences:        q.Preferences.replaceDB(db),
+		SecurityAudit:      q.SecurityAudit.replaceDB(db),
+		SessionHistory:     q.SessionHistory.replaceDB(db),
+		TrashItem:          q.TrashItem.replaceDB(db),
+		Tunnel:             q.Tunnel.replaceDB(db),
+		Vault:              q.Vault.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	Connection IConnectionDo
-	Credential ICredentialDo
-	Group      IGroupDo
-	Metadata   IMetadataDo
+	AuditLog           IAuditLogDo
+	Connection         IConnectionDo
+	ConnectionTemplate IConnectionTemplateDo
+	Credential         ICredentialDo
+	Group              IGroupDo
+	Metadata           IMetadataDo
+	Preferences        IPreferencesDo
+	SecurityAudit      ISecurityAuditDo
+	SessionHistory     ISessionHistoryDo
+	TrashItem          ITrashItemDo
+	Tunnel             ITunnelDo
+	Vault              IVaultDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Connection: q.Connection.WithContext(ctx),
-		Credential: q.Credential.WithContext(ctx),
-		Group:      q.Group.WithContext(ctx),
-		Metadata:   q.Metadata.WithContext(ctx),
+		AuditLog:           q.AuditLog.WithContext(ctx),
+		Connection:         q.Connection.WithContext(ctx),
+		ConnectionTemplate: q.ConnectionTemplate.WithContext(ctx),
+		Credential:         q.Credential.WithContext(ctx),
+		Group:              q.Group.WithContext(ctx),
+		Metadata:           q.Metadata.WithContext(ctx),
+		Preferences:        q.Preferences.WithContext(ctx),
+		SecurityAudit:      q.SecurityAudit.WithContext(ctx),
+		SessionHistory:     q.SessionHistory.WithContext(ctx),
+		TrashItem:          q.TrashItem.WithContext(ctx),
+		Tunnel:             q.Tunnel.WithContext(ctx),
+		Vault:              q.Vault.WithContext(ctx),
 	}
 }
 
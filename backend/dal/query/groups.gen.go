@@ -32,6 +32,24 @@ func newGroup(db *gorm.DB, opts ...gen.DOOption) group {
 	_group.UpdatedAt = field.NewTime(tableName, "updated_at")
 	_group.DeletedAt = field.NewField(tableName, "deleted_at")
 	_group.Name = field.NewString(tableName, "name")
+	_group.ParentID = field.NewUint(tableName, "parent_id")
+	_group.SortOrder = field.NewInt(tableName, "sort_order")
+	_group.Rule = field.NewString(tableName, "rule")
+	_group.FallbackCredentialIDs = field.NewField(tableName, "fallback_credential_ids")
+	_group.TryDefaultIdentities = field.NewBool(tableName, "try_default_identities")
+	_group.CredentialID = field.NewUint(tableName, "credential_id")
+	_group.Port = field.NewUint(tableName, "port")
+	_group.Theme = field.NewString(tableName, "theme")
+	_group.SSHKeyExchanges = field.NewField(tableName, "ssh_key_exchanges")
+	_group.SSHCiphers = field.NewField(tableName, "ssh_ciphers")
+	_group.SSHMACs = field.NewField(tableName, "ssh_ma_cs")
+	_group.SSHPublicKeyAlgorithms = field.NewField(tableName, "ssh_public_key_algorithms")
+	_group.SSHHostKeyAlgorithms = field.NewField(tableName, "ssh_host_key_algorithms")
+	_group.SSHCharset = field.NewString(tableName, "ssh_charset")
+	_group.JumpConnectionID = field.NewUint(tableName, "jump_connection_id")
+	_group.Proxy = field.NewString(tableName, "proxy")
+	_group.ProxyCredentialID = field.NewUint(tableName, "proxy_credential_id")
+	_group.KeepAliveInterval = field.NewInt(tableName, "keep_alive_interval")
 
 	_group.fillFieldMap()
 
@@ -41,12 +59,30 @@ func newGroup(db *gorm.DB, opts ...gen.DOOption) group {
 type group struct {
 	groupDo
 
-	ALL       field.Asterisk
-	ID        field.Uint
-	CreatedAt field.Time
-	UpdatedAt field.Time
-	DeletedAt field.Field
-	Name      field.String
+	ALL                    field.Asterisk
+	ID                     field.Uint
+	CreatedAt              field.Time
+	UpdatedAt              field.Time
+	DeletedAt              field.Field
+	Name                   field.String
+	ParentID               field.Uint
+	SortOrder              field.Int
+	Rule                   field.String
+	FallbackCredentialIDs  field.Field
+	TryDefaultIdentities   field.Bool
+	CredentialID           field.Uint
+	Port                   field.Uint
+	Theme                  field.String
+	SSHKeyExchanges        field.Field
+	SSHCiphers             field.Field
+	SSHMACs                field.Field
+	SSHPublicKeyAlgorithms field.Field
+	SSHHostKeyAlgorithms   field.Field
+	SSHCharset             field.String
+	JumpConnectionID       field.Uint
+	Proxy                  field.String
+	ProxyCredentialID      field.Uint
+	KeepAliveInterval      field.Int
 
 	fieldMap map[string]field.Expr
 }
@@ -68,6 +104,24 @@ func (g *group) updateTableName(table string) *group {
 	g.UpdatedAt = field.NewTime(table, "updated_at")
 	g.DeletedAt = field.NewField(table, "deleted_at")
 	g.Name = field.NewString(table, "name")
+	g.ParentID = field.NewUint(table, "parent_id")
+	g.SortOrder = field.NewInt(table, "sort_order")
+	g.Rule = field.NewString(table, "rule")
+	g.FallbackCredentialIDs = field.NewField(table, "fallback_credential_ids")
+	g.TryDefaultIdentities = field.NewBool(table, "try_default_identities")
+	g.CredentialID = field.NewUint(table, "credential_id")
+	g.Port = field.NewUint(table, "port")
+	g.Theme = field.NewString(table, "theme")
+	g.SSHKeyExchanges = field.NewField(table, "ssh_key_exchanges")
+	g.SSHCiphers = field.NewField(table, "ssh_ciphers")
+	g.SSHMACs = field.NewField(table, "ssh_ma_cs")
+	g.SSHPublicKeyAlgorithms = field.NewField(table, "ssh_public_key_algorithms")
+	g.SSHHostKeyAlgorithms = field.NewField(table, "ssh_host_key_algorithms")
+	g.SSHCharset = field.NewString(table, "ssh_charset")
+	g.JumpConnectionID = field.NewUint(table, "jump_connection_id")
+	g.Proxy = field.NewString(table, "proxy")
+	g.ProxyCredentialID = field.NewUint(table, "proxy_credential_id")
+	g.KeepAliveInterval = field.NewInt(table, "keep_alive_interval")
 
 	g.fillFieldMap()
 
@@ -84,12 +138,30 @@ func (g *group) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (g *group) fillFieldMap() {
-	g.fieldMap = make(map[string]field.Expr, 5)
+	g.fieldMap = make(map[string]field.Expr, 23)
 	g.fieldMap["id"] = g.ID
 	g.fieldMap["created_at"] = g.CreatedAt
 	g.fieldMap["updated_at"] = g.UpdatedAt
 	g.fieldMap["deleted_at"] = g.DeletedAt
 	g.fieldMap["name"] = g.Name
+	g.fieldMap["parent_id"] = g.ParentID
+	g.fieldMap["sort_order"] = g.SortOrder
+	g.fieldMap["rule"] = g.Rule
+	g.fieldMap["fallback_credential_ids"] = g.FallbackCredentialIDs
+	g.fieldMap["try_default_identities"] = g.TryDefaultIdentities
+	g.fieldMap["credential_id"] = g.CredentialID
+	g.fieldMap["port"] = g.Port
+	g.fieldMap["theme"] = g.Theme
+	g.fieldMap["ssh_key_exchanges"] = g.SSHKeyExchanges
+	g.fieldMap["ssh_ciphers"] = g.SSHCiphers
+	g.fieldMap["ssh_ma_cs"] = g.SSHMACs
+	g.fieldMap["ssh_public_key_algorithms"] = g.SSHPublicKeyAlgorithms
+	g.fieldMap["ssh_host_key_algorithms"] = g.SSHHostKeyAlgorithms
+	g.fieldMap["ssh_charset"] = g.SSHCharset
+	g.fieldMap["jump_connection_id"] = g.JumpConnectionID
+	g.fieldMap["proxy"] = g.Proxy
+	g.fieldMap["proxy_credential_id"] = g.ProxyCredentialID
+	g.fieldMap["keep_alive_interval"] = g.KeepAliveInterval
 }
 
 func (g group) clone(db *gorm.DB) group {
@@ -0,0 +1,396 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+)
+
+func newPreferences(db *gorm.DB, opts ...gen.DOOption) preferences {
+	_preferences := preferences{}
+
+	_preferences.preferencesDo.UseDB(db, opts...)
+	_preferences.preferencesDo.UseModel(&model.Preferences{})
+
+	tableName := _preferences.preferencesDo.TableName()
+	_preferences.ALL = field.NewAsterisk(tableName)
+	_preferences.ID = field.NewUint(tableName, "id")
+	_preferences.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_preferences.HistoryRetentionDays = field.NewInt(tableName, "history_retention_days")
+	_preferences.HistoryLimit = field.NewInt(tableName, "history_limit")
+	_preferences.TrashRetentionDays = field.NewInt(tableName, "trash_retention_days")
+
+	_preferences.fillFieldMap()
+
+	return _preferences
+}
+
+type preferences struct {
+	preferencesDo
+
+	ALL                  field.Asterisk
+	ID                   field.Uint
+	UpdatedAt            field.Time
+	HistoryRetentionDays field.Int
+	HistoryLimit         field.Int
+	TrashRetentionDays   field.Int
+
+	fieldMap map[string]field.Expr
+}
+
+func (p preferences) Table(newTableName string) *preferences {
+	p.preferencesDo.UseTable(newTableName)
+	return p.updateTableName(newTableName)
+}
+
+func (p preferences) As(alias string) *preferences {
+	p.preferencesDo.DO = *(p.preferencesDo.As(alias).(*gen.DO))
+	return p.updateTableName(alias)
+}
+
+func (p *preferences) updateTableName(table string) *preferences {
+	p.ALL = field.NewAsterisk(table)
+	p.ID = field.NewUint(table, "id")
+	p.UpdatedAt = field.NewTime(table, "updated_at")
+	p.HistoryRetentionDays = field.NewInt(table, "history_retention_days")
+	p.HistoryLimit = field.NewInt(table, "history_limit")
+	p.TrashRetentionDays = field.NewInt(table, "trash_retention_days")
+
+	p.fillFieldMap()
+
+	return p
+}
+
+func (p *preferences) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := p.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (p *preferences) fillFieldMap() {
+	p.fieldMap = make(map[string]field.Expr, 5)
+	p.fieldMap["id"] = p.ID
+	p.fieldMap["updated_at"] = p.UpdatedAt
+	p.fieldMap["history_retention_days"] = p.HistoryRetentionDays
+	p.fieldMap["history_limit"] = p.HistoryLimit
+	p.fieldMap["trash_retention_days"] = p.TrashRetentionDays
+}
+
+func (p preferences) clone(db *gorm.DB) preferences {
+	p.preferencesDo.ReplaceConnPool(db.Statement.ConnPool)
+	return p
+}
+
+func (p preferences) replaceDB(db *gorm.DB) preferences {
+	p.preferencesDo.ReplaceDB(db)
+	return p
+}
+
+type preferencesDo struct{ gen.DO }
+
+type IPreferencesDo interface {
+	gen.SubQuery
+	Debug() IPreferencesDo
+	WithContext(ctx context.Context) IPreferencesDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IPreferencesDo
+	WriteDB() IPreferencesDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IPreferencesDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IPreferencesDo
+	Not(conds ...gen.Condition) IPreferencesDo
+	Or(conds ...gen.Condition) IPreferencesDo
+	Select(conds ...field.Expr) IPreferencesDo
+	Where(conds ...gen.Condition) IPreferencesDo
+	Order(conds ...field.Expr) IPreferencesDo
+	Distinct(cols ...field.Expr) IPreferencesDo
+	Omit(cols ...field.Expr) IPreferencesDo
+	Join(table schema.Tabler, on ...field.Expr) IPreferencesDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IPreferencesDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IPreferencesDo
+	Group(cols ...field.Expr) IPreferencesDo
+	Having(conds ...gen.Condition) IPreferencesDo
+	Limit(limit int) IPreferencesDo
+	Offset(offset int) IPreferencesDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IPreferencesDo
+	Unscoped() IPreferencesDo
+	Create(values ...*model.Preferences) error
+	CreateInBatches(values []*model.Preferences, batchSize int) error
+	Save(values ...*model.Preferences) error
+	First() (*model.Preferences, error)
+	Take() (*model.Preferences, error)
+	Last() (*model.Preferences, error)
+	Find() ([]*model.Preferences, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Preferences, err error)
+	FindInBatches(result *[]*model.Preferences, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.Preferences) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IPreferencesDo
+	Assign(attrs ...field.AssignExpr) IPreferencesDo
+	Joins(fields ...field.RelationField) IPreferencesDo
+	Preload(fields ...field.RelationField) IPreferencesDo
+	FirstOrInit() (*model.Preferences, error)
+	FirstOrCreate() (*model.Preferences, error)
+	FindByPage(offset int, limit int) (result []*model.Preferences, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IPreferencesDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (p preferencesDo) Debug() IPreferencesDo {
+	return p.withDO(p.DO.Debug())
+}
+
+func (p preferencesDo) WithContext(ctx context.Context) IPreferencesDo {
+	return p.withDO(p.DO.WithContext(ctx))
+}
+
+func (p preferencesDo) ReadDB() IPreferencesDo {
+	return p.Clauses(dbresolver.Read)
+}
+
+func (p preferencesDo) WriteDB() IPreferencesDo {
+	return p.Clauses(dbresolver.Write)
+}
+
+func (p preferencesDo) Session(config *gorm.Session) IPreferencesDo {
+	return p.withDO(p.DO.Session(config))
+}
+
+func (p preferencesDo) Clauses(conds ...clause.Expression) IPreferencesDo {
+	return p.withDO(p.DO.Clauses(conds...))
+}
+
+func (p preferencesDo) Returning(value interface{}, columns ...string) IPreferencesDo {
+	return p.withDO(p.DO.Returning(value, columns...))
+}
+
+func (p preferencesDo) Not(conds ...gen.Condition) IPreferencesDo {
+	return p.withDO(p.DO.Not(conds...))
+}
+
+func (p preferencesDo) Or(conds ...gen.Condition) IPreferencesDo {
+	return p.withDO(p.DO.Or(conds...))
+}
+
+func (p preferencesDo) Select(conds ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Select(conds...))
+}
+
+func (p preferencesDo) Where(conds ...gen.Condition) IPreferencesDo {
+	return p.withDO(p.DO.Where(conds...))
+}
+
+func (p preferencesDo) Order(conds ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Order(conds...))
+}
+
+func (p preferencesDo) Distinct(cols ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Distinct(cols...))
+}
+
+func (p preferencesDo) Omit(cols ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Omit(cols...))
+}
+
+func (p preferencesDo) Join(table schema.Tabler, on ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Join(table, on...))
+}
+
+func (p preferencesDo) LeftJoin(table schema.Tabler, on ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.LeftJoin(table, on...))
+}
+
+func (p preferencesDo) RightJoin(table schema.Tabler, on ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.RightJoin(table, on...))
+}
+
+func (p preferencesDo) Group(cols ...field.Expr) IPreferencesDo {
+	return p.withDO(p.DO.Group(cols...))
+}
+
+func (p preferencesDo) Having(conds ...gen.Condition) IPreferencesDo {
+	return p.withDO(p.DO.Having(conds...))
+}
+
+func (p preferencesDo) Limit(limit int) IPreferencesDo {
+	return p.withDO(p.DO.Limit(limit))
+}
+
+func (p preferencesDo) Offset(offset int) IPreferencesDo {
+	return p.withDO(p.DO.Offset(offset))
+}
+
+func (p preferencesDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IPreferencesDo {
+	return p.withDO(p.DO.Scopes(funcs...))
+}
+
+func (p preferencesDo) Unscoped() IPreferencesDo {
+	return p.withDO(p.DO.Unscoped())
+}
+
+func (p preferencesDo) Create(values ...*model.Preferences) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Create(values)
+}
+
+func (p preferencesDo) CreateInBatches(values []*model.Preferences, batchSize int) error {
+	return p.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (p preferencesDo) Save(values ...*model.Preferences) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Save(values)
+}
+
+func (p preferencesDo) First() (*model.Preferences, error) {
+	if result, err := p.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Preferences), nil
+	}
+}
+
+func (p preferencesDo) Take() (*model.Preferences, error) {
+	if result, err := p.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Preferences), nil
+	}
+}
+
+func (p preferencesDo) Last() (*model.Preferences, error) {
+	if result, err := p.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Preferences), nil
+	}
+}
+
+func (p preferencesDo) Find() ([]*model.Preferences, error) {
+	result, err := p.DO.Find()
+	return result.([]*model.Preferences), err
+}
+
+func (p preferencesDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Preferences, err error) {
+	buf := make([]*model.Preferences, 0, batchSize)
+	err = p.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (p preferencesDo) FindInBatches(result *[]*model.Preferences, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return p.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (p preferencesDo) Attrs(attrs ...field.AssignExpr) IPreferencesDo {
+	return p.withDO(p.DO.Attrs(attrs...))
+}
+
+func (p preferencesDo) Assign(attrs ...field.AssignExpr) IPreferencesDo {
+	return p.withDO(p.DO.Assign(attrs...))
+}
+
+func (p preferencesDo) Joins(fields ...field.RelationField) IPreferencesDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Joins(_f))
+	}
+	return &p
+}
+
+func (p preferencesDo) Preload(fields ...field.RelationField) IPreferencesDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Preload(_f))
+	}
+	return &p
+}
+
+func (p preferencesDo) FirstOrInit() (*model.Preferences, error) {
+	if result, err := p.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Preferences), nil
+	}
+}
+
+func (p preferencesDo) FirstOrCreate() (*model.Preferences, error) {
+	if result, err := p.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Preferences), nil
+	}
+}
+
+func (p preferencesDo) FindByPage(offset int, limit int) (result []*model.Preferences, count int64, err error) {
+	result, err = p.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = p.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (p preferencesDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = p.Count()
+	if err != nil {
+		return
+	}
+
+	err = p.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (p preferencesDo) Scan(result interface{}) (err error) {
+	return p.DO.Scan(result)
+}
+
+func (p preferencesDo) Delete(models ...*model.Preferences) (result gen.ResultInfo, err error) {
+	return p.DO.Delete(models)
+}
+
+func (p *preferencesDo) withDO(do gen.Dao) *preferencesDo {
+	p.DO = *do.(*gen.DO)
+	return p
+}
@@ -0,0 +1,416 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+)
+
+func newSecurityAudit(db *gorm.DB, opts ...gen.DOOption) securityAudit {
+	_securityAudit := securityAudit{}
+
+	_securityAudit.securityAuditDo.UseDB(db, opts...)
+	_securityAudit.securityAuditDo.UseModel(&model.SecurityAudit{})
+
+	tableName := _securityAudit.securityAuditDo.TableName()
+	_securityAudit.ALL = field.NewAsterisk(tableName)
+	_securityAudit.ID = field.NewUint(tableName, "id")
+	_securityAudit.CreatedAt = field.NewTime(tableName, "created_at")
+	_securityAudit.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_securityAudit.DeletedAt = field.NewField(tableName, "deleted_at")
+	_securityAudit.ConnectionID = field.NewUint(tableName, "connection_id")
+	_securityAudit.ServerVersion = field.NewString(tableName, "server_version")
+	_securityAudit.Offered = field.NewField(tableName, "offered")
+	_securityAudit.Expected = field.NewField(tableName, "expected")
+	_securityAudit.Findings = field.NewField(tableName, "findings")
+	_securityAudit.Rating = field.NewString(tableName, "rating")
+
+	_securityAudit.fillFieldMap()
+
+	return _securityAudit
+}
+
+type securityAudit struct {
+	securityAuditDo
+
+	ALL           field.Asterisk
+	ID            field.Uint
+	CreatedAt     field.Time
+	UpdatedAt     field.Time
+	DeletedAt     field.Field
+	ConnectionID  field.Uint
+	ServerVersion field.String
+	Offered       field.Field
+	Expected      field.Field
+	Findings      field.Field
+	Rating        field.String
+
+	fieldMap map[string]field.Expr
+}
+
+func (s securityAudit) Table(newTableName string) *securityAudit {
+	s.securityAuditDo.UseTable(newTableName)
+	return s.updateTableName(newTableName)
+}
+
+func (s securityAudit) As(alias string) *securityAudit {
+	s.securityAuditDo.DO = *(s.securityAuditDo.As(alias).(*gen.DO))
+	return s.updateTableName(alias)
+}
+
+func (s *securityAudit) updateTableName(table string) *securityAudit {
+	s.ALL = field.NewAsterisk(table)
+	s.ID = field.NewUint(table, "id")
+	s.CreatedAt = field.NewTime(table, "created_at")
+	s.UpdatedAt = field.NewTime(table, "updated_at")
+	s.DeletedAt = field.NewField(table, "deleted_at")
+	s.ConnectionID = field.NewUint(table, "connection_id")
+	s.ServerVersion = field.NewString(table, "server_version")
+	s.Offered = field.NewField(table, "offered")
+	s.Expected = field.NewField(table, "expected")
+	s.Findings = field.NewField(table, "findings")
+	s.Rating = field.NewString(table, "rating")
+
+	s.fillFieldMap()
+
+	return s
+}
+
+func (s *securityAudit) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := s.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (s *securityAudit) fillFieldMap() {
+	s.fieldMap = make(map[string]field.Expr, 10)
+	s.fieldMap["id"] = s.ID
+	s.fieldMap["created_at"] = s.CreatedAt
+	s.fieldMap["updated_at"] = s.UpdatedAt
+	s.fieldMap["deleted_at"] = s.DeletedAt
+	s.fieldMap["connection_id"] = s.ConnectionID
+	s.fieldMap["server_version"] = s.ServerVersion
+	s.fieldMap["offered"] = s.Offered
+	s.fieldMap["expected"] = s.Expected
+	s.fieldMap["findings"] = s.Findings
+	s.fieldMap["rating"] = s.Rating
+}
+
+func (s securityAudit) clone(db *gorm.DB) securityAudit {
+	s.securityAuditDo.ReplaceConnPool(db.Statement.ConnPool)
+	return s
+}
+
+func (s securityAudit) replaceDB(db *gorm.DB) securityAudit {
+	s.securityAuditDo.ReplaceDB(db)
+	return s
+}
+
+type securityAuditDo struct{ gen.DO }
+
+type ISecurityAuditDo interface {
+	gen.SubQuery
+	Debug() ISecurityAuditDo
+	WithContext(ctx context.Context) ISecurityAuditDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ISecurityAuditDo
+	WriteDB() ISecurityAuditDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ISecurityAuditDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ISecurityAuditDo
+	Not(conds ...gen.Condition) ISecurityAuditDo
+	Or(conds ...gen.Condition) ISecurityAuditDo
+	Select(conds ...field.Expr) ISecurityAuditDo
+	Where(conds ...gen.Condition) ISecurityAuditDo
+	Order(conds ...field.Expr) ISecurityAuditDo
+	Distinct(cols ...field.Expr) ISecurityAuditDo
+	Omit(cols ...field.Expr) ISecurityAuditDo
+	Join(table schema.Tabler, on ...field.Expr) ISecurityAuditDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ISecurityAuditDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ISecurityAuditDo
+	Group(cols ...field.Expr) ISecurityAuditDo
+	Having(conds ...gen.Condition) ISecurityAuditDo
+	Limit(limit int) ISecurityAuditDo
+	Offset(offset int) ISecurityAuditDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ISecurityAuditDo
+	Unscoped() ISecurityAuditDo
+	Create(values ...*model.SecurityAudit) error
+	CreateInBatches(values []*model.SecurityAudit, batchSize int) error
+	Save(values ...*model.SecurityAudit) error
+	First() (*model.SecurityAudit, error)
+	Take() (*model.SecurityAudit, error)
+	Last() (*model.SecurityAudit, error)
+	Find() ([]*model.SecurityAudit, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.SecurityAudit, err error)
+	FindInBatches(result *[]*model.SecurityAudit, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.SecurityAudit) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ISecurityAuditDo
+	Assign(attrs ...field.AssignExpr) ISecurityAuditDo
+	Joins(fields ...field.RelationField) ISecurityAuditDo
+	Preload(fields ...field.RelationField) ISecurityAuditDo
+	FirstOrInit() (*model.SecurityAudit, error)
+	FirstOrCreate() (*model.SecurityAudit, error)
+	FindByPage(offset int, limit int) (result []*model.SecurityAudit, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ISecurityAuditDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (s securityAuditDo) Debug() ISecurityAuditDo {
+	return s.withDO(s.DO.Debug())
+}
+
+func (s securityAuditDo) WithContext(ctx context.Context) ISecurityAuditDo {
+	return s.withDO(s.DO.WithContext(ctx))
+}
+
+func (s securityAuditDo) ReadDB() ISecurityAuditDo {
+	return s.Clauses(dbresolver.Read)
+}
+
+func (s securityAuditDo) WriteDB() ISecurityAuditDo {
+	return s.Clauses(dbresolver.Write)
+}
+
+func (s securityAuditDo) Session(config *gorm.Session) ISecurityAuditDo {
+	return s.withDO(s.DO.Session(config))
+}
+
+func (s securityAuditDo) Clauses(conds ...clause.Expression) ISecurityAuditDo {
+	return s.withDO(s.DO.Clauses(conds...))
+}
+
+func (s securityAuditDo) Returning(value interface{}, columns ...string) ISecurityAuditDo {
+	return s.withDO(s.DO.Returning(value, columns...))
+}
+
+func (s securityAuditDo) Not(conds ...gen.Condition) ISecurityAuditDo {
+	return s.withDO(s.DO.Not(conds...))
+}
+
+func (s securityAuditDo) Or(conds ...gen.Condition) ISecurityAuditDo {
+	return s.withDO(s.DO.Or(conds...))
+}
+
+func (s securityAuditDo) Select(conds ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Select(conds...))
+}
+
+func (s securityAuditDo) Where(conds ...gen.Condition) ISecurityAuditDo {
+	return s.withDO(s.DO.Where(conds...))
+}
+
+func (s securityAuditDo) Order(conds ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Order(conds...))
+}
+
+func (s securityAuditDo) Distinct(cols ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Distinct(cols...))
+}
+
+func (s securityAuditDo) Omit(cols ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Omit(cols...))
+}
+
+func (s securityAuditDo) Join(table schema.Tabler, on ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Join(table, on...))
+}
+
+func (s securityAuditDo) LeftJoin(table schema.Tabler, on ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.LeftJoin(table, on...))
+}
+
+func (s securityAuditDo) RightJoin(table schema.Tabler, on ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.RightJoin(table, on...))
+}
+
+func (s securityAuditDo) Group(cols ...field.Expr) ISecurityAuditDo {
+	return s.withDO(s.DO.Group(cols...))
+}
+
+func (s securityAuditDo) Having(conds ...gen.Condition) ISecurityAuditDo {
+	return s.withDO(s.DO.Having(conds...))
+}
+
+func (s securityAuditDo) Limit(limit int) ISecurityAuditDo {
+	return s.withDO(s.DO.Limit(limit))
+}
+
+func (s securityAuditDo) Offset(offset int) ISecurityAuditDo {
+	return s.withDO(s.DO.Offset(offset))
+}
+
+func (s securityAuditDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ISecurityAuditDo {
+	return s.withDO(s.DO.Scopes(funcs...))
+}
+
+func (s securityAuditDo) Unscoped() ISecurityAuditDo {
+	return s.withDO(s.DO.Unscoped())
+}
+
+func (s securityAuditDo) Create(values ...*model.SecurityAudit) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Create(values)
+}
+
+func (s securityAuditDo) CreateInBatches(values []*model.SecurityAudit, batchSize int) error {
+	return s.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (s securityAuditDo) Save(values ...*model.SecurityAudit) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Save(values)
+}
+
+func (s securityAuditDo) First() (*model.SecurityAudit, error) {
+	if result, err := s.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SecurityAudit), nil
+	}
+}
+
+func (s securityAuditDo) Take() (*model.SecurityAudit, error) {
+	if result, err := s.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SecurityAudit), nil
+	}
+}
+
+func (s securityAuditDo) Last() (*model.SecurityAudit, error) {
+	if result, err := s.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SecurityAudit), nil
+	}
+}
+
+func (s securityAuditDo) Find() ([]*model.SecurityAudit, error) {
+	result, err := s.DO.Find()
+	return result.([]*model.SecurityAudit), err
+}
+
+func (s securityAuditDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.SecurityAudit, err error) {
+	buf := make([]*model.SecurityAudit, 0, batchSize)
+	err = s.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (s securityAuditDo) FindInBatches(result *[]*model.SecurityAudit, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return s.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (s securityAuditDo) Attrs(attrs ...field.AssignExpr) ISecurityAuditDo {
+	return s.withDO(s.DO.Attrs(attrs...))
+}
+
+func (s securityAuditDo) Assign(attrs ...field.AssignExpr) ISecurityAuditDo {
+	return s.withDO(s.DO.Assign(attrs...))
+}
+
+func (s securityAuditDo) Joins(fields ...field.RelationField) ISecurityAuditDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Joins(_f))
+	}
+	return &s
+}
+
+func (s securityAuditDo) Preload(fields ...field.RelationField) ISecurityAuditDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Preload(_f))
+	}
+	return &s
+}
+
+func (s securityAuditDo) FirstOrInit() (*model.SecurityAudit, error) {
+	if result, err := s.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SecurityAudit), nil
+	}
+}
+
+func (s securityAuditDo) FirstOrCreate() (*model.SecurityAudit, error) {
+	if result, err := s.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SecurityAudit), nil
+	}
+}
+
+func (s securityAuditDo) FindByPage(offset int, limit int) (result []*model.SecurityAudit, count int64, err error) {
+	result, err = s.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = s.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (s securityAuditDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = s.Count()
+	if err != nil {
+		return
+	}
+
+	err = s.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (s securityAuditDo) Scan(result interface{}) (err error) {
+	return s.DO.Scan(result)
+}
+
+func (s securityAuditDo) Delete(models ...*model.SecurityAudit) (result gen.ResultInfo, err error) {
+	return s.DO.Delete(models)
+}
+
+func (s *securityAuditDo) withDO(do gen.Dao) *securityAuditDo {
+	s.DO = *do.(*gen.DO)
+	return s
+}
@@ -0,0 +1,416 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+)
+
+func newSessionHistory(db *gorm.DB, opts ...gen.DOOption) sessionHistory {
+	_sessionHistory := sessionHistory{}
+
+	_sessionHistory.sessionHistoryDo.UseDB(db, opts...)
+	_sessionHistory.sessionHistoryDo.UseModel(&model.SessionHistory{})
+
+	tableName := _sessionHistory.sessionHistoryDo.TableName()
+	_sessionHistory.ALL = field.NewAsterisk(tableName)
+	_sessionHistory.ID = field.NewUint(tableName, "id")
+	_sessionHistory.ConnectionID = field.NewUint(tableName, "connection_id")
+	_sessionHistory.Label = field.NewString(tableName, "label")
+	_sessionHistory.Host = field.NewString(tableName, "host")
+	_sessionHistory.Protocol = field.NewString(tableName, "protocol")
+	_sessionHistory.StartedAt = field.NewTime(tableName, "started_at")
+	_sessionHistory.ConnectedAt = field.NewTime(tableName, "connected_at")
+	_sessionHistory.EndedAt = field.NewTime(tableName, "ended_at")
+	_sessionHistory.Duration = field.NewInt64(tableName, "duration")
+	_sessionHistory.ExitReason = field.NewString(tableName, "exit_reason")
+
+	_sessionHistory.fillFieldMap()
+
+	return _sessionHistory
+}
+
+type sessionHistory struct {
+	sessionHistoryDo
+
+	ALL          field.Asterisk
+	ID           field.Uint
+	ConnectionID field.Uint
+	Label        field.String
+	Host         field.String
+	Protocol     field.String
+	StartedAt    field.Time
+	ConnectedAt  field.Time
+	EndedAt      field.Time
+	Duration     field.Int64
+	ExitReason   field.String
+
+	fieldMap map[string]field.Expr
+}
+
+func (s sessionHistory) Table(newTableName string) *sessionHistory {
+	s.sessionHistoryDo.UseTable(newTableName)
+	return s.updateTableName(newTableName)
+}
+
+func (s sessionHistory) As(alias string) *sessionHistory {
+	s.sessionHistoryDo.DO = *(s.sessionHistoryDo.As(alias).(*gen.DO))
+	return s.updateTableName(alias)
+}
+
+func (s *sessionHistory) updateTableName(table string) *sessionHistory {
+	s.ALL = field.NewAsterisk(table)
+	s.ID = field.NewUint(table, "id")
+	s.ConnectionID = field.NewUint(table, "connection_id")
+	s.Label = field.NewString(table, "label")
+	s.Host = field.NewString(table, "host")
+	s.Protocol = field.NewString(table, "protocol")
+	s.StartedAt = field.NewTime(table, "started_at")
+	s.ConnectedAt = field.NewTime(table, "connected_at")
+	s.EndedAt = field.NewTime(table, "ended_at")
+	s.Duration = field.NewInt64(table, "duration")
+	s.ExitReason = field.NewString(table, "exit_reason")
+
+	s.fillFieldMap()
+
+	return s
+}
+
+func (s *sessionHistory) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := s.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (s *sessionHistory) fillFieldMap() {
+	s.fieldMap = make(map[string]field.Expr, 10)
+	s.fieldMap["id"] = s.ID
+	s.fieldMap["connection_id"] = s.ConnectionID
+	s.fieldMap["label"] = s.Label
+	s.fieldMap["host"] = s.Host
+	s.fieldMap["protocol"] = s.Protocol
+	s.fieldMap["started_at"] = s.StartedAt
+	s.fieldMap["connected_at"] = s.ConnectedAt
+	s.fieldMap["ended_at"] = s.EndedAt
+	s.fieldMap["duration"] = s.Duration
+	s.fieldMap["exit_reason"] = s.ExitReason
+}
+
+func (s sessionHistory) clone(db *gorm.DB) sessionHistory {
+	s.sessionHistoryDo.ReplaceConnPool(db.Statement.ConnPool)
+	return s
+}
+
+func (s sessionHistory) replaceDB(db *gorm.DB) sessionHistory {
+	s.sessionHistoryDo.ReplaceDB(db)
+	return s
+}
+
+type sessionHistoryDo struct{ gen.DO }
+
+type ISessionHistoryDo interface {
+	gen.SubQuery
+	Debug() ISessionHistoryDo
+	WithContext(ctx context.Context) ISessionHistoryDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ISessionHistoryDo
+	WriteDB() ISessionHistoryDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ISessionHistoryDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ISessionHistoryDo
+	Not(conds ...gen.Condition) ISessionHistoryDo
+	Or(conds ...gen.Condition) ISessionHistoryDo
+	Select(conds ...field.Expr) ISessionHistoryDo
+	Where(conds ...gen.Condition) ISessionHistoryDo
+	Order(conds ...field.Expr) ISessionHistoryDo
+	Distinct(cols ...field.Expr) ISessionHistoryDo
+	Omit(cols ...field.Expr) ISessionHistoryDo
+	Join(table schema.Tabler, on ...field.Expr) ISessionHistoryDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ISessionHistoryDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ISessionHistoryDo
+	Group(cols ...field.Expr) ISessionHistoryDo
+	Having(conds ...gen.Condition) ISessionHistoryDo
+	Limit(limit int) ISessionHistoryDo
+	Offset(offset int) ISessionHistoryDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ISessionHistoryDo
+	Unscoped() ISessionHistoryDo
+	Create(values ...*model.SessionHistory) error
+	CreateInBatches(values []*model.SessionHistory, batchSize int) error
+	Save(values ...*model.SessionHistory) error
+	First() (*model.SessionHistory, error)
+	Take() (*model.SessionHistory, error)
+	Last() (*model.SessionHistory, error)
+	Find() ([]*model.SessionHistory, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.SessionHistory, err error)
+	FindInBatches(result *[]*model.SessionHistory, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.SessionHistory) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ISessionHistoryDo
+	Assign(attrs ...field.AssignExpr) ISessionHistoryDo
+	Joins(fields ...field.RelationField) ISessionHistoryDo
+	Preload(fields ...field.RelationField) ISessionHistoryDo
+	FirstOrInit() (*model.SessionHistory, error)
+	FirstOrCreate() (*model.SessionHistory, error)
+	FindByPage(offset int, limit int) (result []*model.SessionHistory, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ISessionHistoryDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (s sessionHistoryDo) Debug() ISessionHistoryDo {
+	return s.withDO(s.DO.Debug())
+}
+
+func (s sessionHistoryDo) WithContext(ctx context.Context) ISessionHistoryDo {
+	return s.withDO(s.DO.WithContext(ctx))
+}
+
+func (s sessionHistoryDo) ReadDB() ISessionHistoryDo {
+	return s.Clauses(dbresolver.Read)
+}
+
+func (s sessionHistoryDo) WriteDB() ISessionHistoryDo {
+	return s.Clauses(dbresolver.Write)
+}
+
+func (s sessionHistoryDo) Session(config *gorm.Session) ISessionHistoryDo {
+	return s.withDO(s.DO.Session(config))
+}
+
+func (s sessionHistoryDo) Clauses(conds ...clause.Expression) ISessionHistoryDo {
+	return s.withDO(s.DO.Clauses(conds...))
+}
+
+func (s sessionHistoryDo) Returning(value interface{}, columns ...string) ISessionHistoryDo {
+	return s.withDO(s.DO.Returning(value, columns...))
+}
+
+func (s sessionHistoryDo) Not(conds ...gen.Condition) ISessionHistoryDo {
+	return s.withDO(s.DO.Not(conds...))
+}
+
+func (s sessionHistoryDo) Or(conds ...gen.Condition) ISessionHistoryDo {
+	return s.withDO(s.DO.Or(conds...))
+}
+
+func (s sessionHistoryDo) Select(conds ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Select(conds...))
+}
+
+func (s sessionHistoryDo) Where(conds ...gen.Condition) ISessionHistoryDo {
+	return s.withDO(s.DO.Where(conds...))
+}
+
+func (s sessionHistoryDo) Order(conds ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Order(conds...))
+}
+
+func (s sessionHistoryDo) Distinct(cols ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Distinct(cols...))
+}
+
+func (s sessionHistoryDo) Omit(cols ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Omit(cols...))
+}
+
+func (s sessionHistoryDo) Join(table schema.Tabler, on ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Join(table, on...))
+}
+
+func (s sessionHistoryDo) LeftJoin(table schema.Tabler, on ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.LeftJoin(table, on...))
+}
+
+func (s sessionHistoryDo) RightJoin(table schema.Tabler, on ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.RightJoin(table, on...))
+}
+
+func (s sessionHistoryDo) Group(cols ...field.Expr) ISessionHistoryDo {
+	return s.withDO(s.DO.Group(cols...))
+}
+
+func (s sessionHistoryDo) Having(conds ...gen.Condition) ISessionHistoryDo {
+	return s.withDO(s.DO.Having(conds...))
+}
+
+func (s sessionHistoryDo) Limit(limit int) ISessionHistoryDo {
+	return s.withDO(s.DO.Limit(limit))
+}
+
+func (s sessionHistoryDo) Offset(offset int) ISessionHistoryDo {
+	return s.withDO(s.DO.Offset(offset))
+}
+
+func (s sessionHistoryDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ISessionHistoryDo {
+	return s.withDO(s.DO.Scopes(funcs...))
+}
+
+func (s sessionHistoryDo) Unscoped() ISessionHistoryDo {
+	return s.withDO(s.DO.Unscoped())
+}
+
+func (s sessionHistoryDo) Create(values ...*model.SessionHistory) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Create(values)
+}
+
+func (s sessionHistoryDo) CreateInBatches(values []*model.SessionHistory, batchSize int) error {
+	return s.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (s sessionHistoryDo) Save(values ...*model.SessionHistory) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Save(values)
+}
+
+func (s sessionHistoryDo) First() (*model.SessionHistory, error) {
+	if result, err := s.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SessionHistory), nil
+	}
+}
+
+func (s sessionHistoryDo) Take() (*model.SessionHistory, error) {
+	if result, err := s.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SessionHistory), nil
+	}
+}
+
+func (s sessionHistoryDo) Last() (*model.SessionHistory, error) {
+	if result, err := s.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SessionHistory), nil
+	}
+}
+
+func (s sessionHistoryDo) Find() ([]*model.SessionHistory, error) {
+	result, err := s.DO.Find()
+	return result.([]*model.SessionHistory), err
+}
+
+func (s sessionHistoryDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.SessionHistory, err error) {
+	buf := make([]*model.SessionHistory, 0, batchSize)
+	err = s.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (s sessionHistoryDo) FindInBatches(result *[]*model.SessionHistory, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return s.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (s sessionHistoryDo) Attrs(attrs ...field.AssignExpr) ISessionHistoryDo {
+	return s.withDO(s.DO.Attrs(attrs...))
+}
+
+func (s sessionHistoryDo) Assign(attrs ...field.AssignExpr) ISessionHistoryDo {
+	return s.withDO(s.DO.Assign(attrs...))
+}
+
+func (s sessionHistoryDo) Joins(fields ...field.RelationField) ISessionHistoryDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Joins(_f))
+	}
+	return &s
+}
+
+func (s sessionHistoryDo) Preload(fields ...field.RelationField) ISessionHistoryDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Preload(_f))
+	}
+	return &s
+}
+
+func (s sessionHistoryDo) FirstOrInit() (*model.SessionHistory, error) {
+	if result, err := s.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SessionHistory), nil
+	}
+}
+
+func (s sessionHistoryDo) FirstOrCreate() (*model.SessionHistory, error) {
+	if result, err := s.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.SessionHistory), nil
+	}
+}
+
+func (s sessionHistoryDo) FindByPage(offset int, limit int) (result []*model.SessionHistory, count int64, err error) {
+	result, err = s.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = s.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (s sessionHistoryDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = s.Count()
+	if err != nil {
+		return
+	}
+
+	err = s.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (s sessionHistoryDo) Scan(result interface{}) (err error) {
+	return s.DO.Scan(result)
+}
+
+func (s sessionHistoryDo) Delete(models ...*model.SessionHistory) (result gen.ResultInfo, err error) {
+	return s.DO.Delete(models)
+}
+
+func (s *sessionHistoryDo) withDO(do gen.Dao) *sessionHistoryDo {
+	s.DO = *do.(*gen.DO)
+	return s
+}
@@ -0,0 +1,432 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+)
+
+func newTunnel(db *gorm.DB, opts ...gen.DOOption) tunnel {
+	_tunnel := tunnel{}
+
+	_tunnel.tunnelDo.UseDB(db, opts...)
+	_tunnel.tunnelDo.UseModel(&model.Tunnel{})
+
+	tableName := _tunnel.tunnelDo.TableName()
+	_tunnel.ALL = field.NewAsterisk(tableName)
+	_tunnel.ID = field.NewUint(tableName, "id")
+	_tunnel.CreatedAt = field.NewTime(tableName, "created_at")
+	_tunnel.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_tunnel.DeletedAt = field.NewField(tableName, "deleted_at")
+	_tunnel.Name = field.NewString(tableName, "name")
+	_tunnel.ConnectionID = field.NewUint(tableName, "connection_id")
+	_tunnel.Type = field.NewString(tableName, "type")
+	_tunnel.Bind = field.NewString(tableName, "bind")
+	_tunnel.Target = field.NewString(tableName, "target")
+	_tunnel.RemoteDNS = field.NewBool(tableName, "remote_dns")
+	_tunnel.AutoStart = field.NewBool(tableName, "auto_start")
+	_tunnel.Username = field.NewString(tableName, "username")
+	_tunnel.PasswordCiphertext = field.NewString(tableName, "password_ciphertext")
+	_tunnel.PasswordSalt = field.NewString(tableName, "password_salt")
+
+	_tunnel.fillFieldMap()
+
+	return _tunnel
+}
+
+type tunnel struct {
+	tunnelDo
+
+	ALL                field.Asterisk
+	ID                 field.Uint
+	CreatedAt          field.Time
+	UpdatedAt          field.Time
+	DeletedAt          field.Field
+	Name               field.String
+	ConnectionID       field.Uint
+	Type               field.String
+	Bind               field.String
+	Target             field.String
+	RemoteDNS          field.Bool
+	AutoStart          field.Bool
+	Username           field.String
+	PasswordCiphertext field.String
+	PasswordSalt       field.String
+
+	fieldMap map[string]field.Expr
+}
+
+func (t tunnel) Table(newTableName string) *tunnel {
+	t.tunnelDo.UseTable(newTableName)
+	return t.updateTableName(newTableName)
+}
+
+func (t tunnel) As(alias string) *tunnel {
+	t.tunnelDo.DO = *(t.tunnelDo.As(alias).(*gen.DO))
+	return t.updateTableName(alias)
+}
+
+func (t *tunnel) updateTableName(table string) *tunnel {
+	t.ALL = field.NewAsterisk(table)
+	t.ID = field.NewUint(table, "id")
+	t.CreatedAt = field.NewTime(table, "created_at")
+	t.UpdatedAt = field.NewTime(table, "updated_at")
+	t.DeletedAt = field.NewField(table, "deleted_at")
+	t.Name = field.NewString(table, "name")
+	t.ConnectionID = field.NewUint(table, "connection_id")
+	t.Type = field.NewString(table, "type")
+	t.Bind = field.NewString(table, "bind")
+	t.Target = field.NewString(table, "target")
+	t.RemoteDNS = field.NewBool(table, "remote_dns")
+	t.AutoStart = field.NewBool(table, "auto_start")
+	t.Username = field.NewString(table, "username")
+	t.PasswordCiphertext = field.NewString(table, "password_ciphertext")
+	t.PasswordSalt = field.NewString(table, "password_salt")
+
+	t.fillFieldMap()
+
+	return t
+}
+
+func (t *tunnel) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := t.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (t *tunnel) fillFieldMap() {
+	t.fieldMap = make(map[string]field.Expr, 14)
+	t.fieldMap["id"] = t.ID
+	t.fieldMap["created_at"] = t.CreatedAt
+	t.fieldMap["updated_at"] = t.UpdatedAt
+	t.fieldMap["deleted_at"] = t.DeletedAt
+	t.fieldMap["name"] = t.Name
+	t.fieldMap["connection_id"] = t.ConnectionID
+	t.fieldMap["type"] = t.Type
+	t.fieldMap["bind"] = t.Bind
+	t.fieldMap["target"] = t.Target
+	t.fieldMap["remote_dns"] = t.RemoteDNS
+	t.fieldMap["auto_start"] = t.AutoStart
+	t.fieldMap["username"] = t.Username
+	t.fieldMap["password_ciphertext"] = t.PasswordCiphertext
+	t.fieldMap["password_salt"] = t.PasswordSalt
+}
+
+func (t tunnel) clone(db *gorm.DB) tunnel {
+	t.tunnelDo.ReplaceConnPool(db.Statement.ConnPool)
+	return t
+}
+
+func (t tunnel) replaceDB(db *gorm.DB) tunnel {
+	t.tunnelDo.ReplaceDB(db)
+	return t
+}
+
+type tunnelDo struct{ gen.DO }
+
+type ITunnelDo interface {
+	gen.SubQuery
+	Debug() ITunnelDo
+	WithContext(ctx context.Context) ITunnelDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ITunnelDo
+	WriteDB() ITunnelDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ITunnelDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ITunnelDo
+	Not(conds ...gen.Condition) ITunnelDo
+	Or(conds ...gen.Condition) ITunnelDo
+	Select(conds ...field.Expr) ITunnelDo
+	Where(conds ...gen.Condition) ITunnelDo
+	Order(conds ...field.Expr) ITunnelDo
+	Distinct(cols ...field.Expr) ITunnelDo
+	Omit(cols ...field.Expr) ITunnelDo
+	Join(table schema.Tabler, on ...field.Expr) ITunnelDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ITunnelDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ITunnelDo
+	Group(cols ...field.Expr) ITunnelDo
+	Having(conds ...gen.Condition) ITunnelDo
+	Limit(limit int) ITunnelDo
+	Offset(offset int) ITunnelDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ITunnelDo
+	Unscoped() ITunnelDo
+	Create(values ...*model.Tunnel) error
+	CreateInBatches(values []*model.Tunnel, batchSize int) error
+	Save(values ...*model.Tunnel) error
+	First() (*model.Tunnel, error)
+	Take() (*model.Tunnel, error)
+	Last() (*model.Tunnel, error)
+	Find() ([]*model.Tunnel, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Tunnel, err error)
+	FindInBatches(result *[]*model.Tunnel, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.Tunnel) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ITunnelDo
+	Assign(attrs ...field.AssignExpr) ITunnelDo
+	Joins(fields ...field.RelationField) ITunnelDo
+	Preload(fields ...field.RelationField) ITunnelDo
+	FirstOrInit() (*model.Tunnel, error)
+	FirstOrCreate() (*model.Tunnel, error)
+	FindByPage(offset int, limit int) (result []*model.Tunnel, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ITunnelDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (t tunnelDo) Debug() ITunnelDo {
+	return t.withDO(t.DO.Debug())
+}
+
+func (t tunnelDo) WithContext(ctx context.Context) ITunnelDo {
+	return t.withDO(t.DO.WithContext(ctx))
+}
+
+func (t tunnelDo) ReadDB() ITunnelDo {
+	return t.Clauses(dbresolver.Read)
+}
+
+func (t tunnelDo) WriteDB() ITunnelDo {
+	return t.Clauses(dbresolver.Write)
+}
+
+func (t tunnelDo) Session(config *gorm.Session) ITunnelDo {
+	return t.withDO(t.DO.Session(config))
+}
+
+func (t tunnelDo) Clauses(conds ...clause.Expression) ITunnelDo {
+	return t.withDO(t.DO.Clauses(conds...))
+}
+
+func (t tunnelDo) Returning(value interface{}, columns ...string) ITunnelDo {
+	return t.withDO(t.DO.Returning(value, columns...))
+}
+
+func (t tunnelDo) Not(conds ...gen.Condition) ITunnelDo {
+	return t.withDO(t.DO.Not(conds...))
+}
+
+func (t tunnelDo) Or(conds ...gen.Condition) ITunnelDo {
+	return t.withDO(t.DO.Or(conds...))
+}
+
+func (t tunnelDo) Select(conds ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Select(conds...))
+}
+
+func (t tunnelDo) Where(conds ...gen.Condition) ITunnelDo {
+	return t.withDO(t.DO.Where(conds...))
+}
+
+func (t tunnelDo) Order(conds ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Order(conds...))
+}
+
+func (t tunnelDo) Distinct(cols ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Distinct(cols...))
+}
+
+func (t tunnelDo) Omit(cols ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Omit(cols...))
+}
+
+func (t tunnelDo) Join(table schema.Tabler, on ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Join(table, on...))
+}
+
+func (t tunnelDo) LeftJoin(table schema.Tabler, on ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.LeftJoin(table, on...))
+}
+
+func (t tunnelDo) RightJoin(table schema.Tabler, on ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.RightJoin(table, on...))
+}
+
+func (t tunnelDo) Group(cols ...field.Expr) ITunnelDo {
+	return t.withDO(t.DO.Group(cols...))
+}
+
+func (t tunnelDo) Having(conds ...gen.Condition) ITunnelDo {
+	return t.withDO(t.DO.Having(conds...))
+}
+
+func (t tunnelDo) Limit(limit int) ITunnelDo {
+	return t.withDO(t.DO.Limit(limit))
+}
+
+func (t tunnelDo) Offset(offset int) ITunnelDo {
+	return t.withDO(t.DO.Offset(offset))
+}
+
+func (t tunnelDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ITunnelDo {
+	return t.withDO(t.DO.Scopes(funcs...))
+}
+
+func (t tunnelDo) Unscoped() ITunnelDo {
+	return t.withDO(t.DO.Unscoped())
+}
+
+func (t tunnelDo) Create(values ...*model.Tunnel) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Create(values)
+}
+
+func (t tunnelDo) CreateInBatches(values []*model.Tunnel, batchSize int) error {
+	return t.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (t tunnelDo) Save(values ...*model.Tunnel) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return t.DO.Save(values)
+}
+
+func (t tunnelDo) First() (*model.Tunnel, error) {
+	if result, err := t.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Tunnel), nil
+	}
+}
+
+func (t tunnelDo) Take() (*model.Tunnel, error) {
+	if result, err := t.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Tunnel), nil
+	}
+}
+
+func (t tunnelDo) Last() (*model.Tunnel, error) {
+	if result, err := t.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Tunnel), nil
+	}
+}
+
+func (t tunnelDo) Find() ([]*model.Tunnel, error) {
+	result, err := t.DO.Find()
+	return result.([]*model.Tunnel), err
+}
+
+func (t tunnelDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Tunnel, err error) {
+	buf := make([]*model.Tunnel, 0, batchSize)
+	err = t.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (t tunnelDo) FindInBatches(result *[]*model.Tunnel, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return t.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (t tunnelDo) Attrs(attrs ...field.AssignExpr) ITunnelDo {
+	return t.withDO(t.DO.Attrs(attrs...))
+}
+
+func (t tunnelDo) Assign(attrs ...field.AssignExpr) ITunnelDo {
+	return t.withDO(t.DO.Assign(attrs...))
+}
+
+func (t tunnelDo) Joins(fields ...field.RelationField) ITunnelDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Joins(_f))
+	}
+	return &t
+}
+
+func (t tunnelDo) Preload(fields ...field.RelationField) ITunnelDo {
+	for _, _f := range fields {
+		t = *t.withDO(t.DO.Preload(_f))
+	}
+	return &t
+}
+
+func (t tunnelDo) FirstOrInit() (*model.Tunnel, error) {
+	if result, err := t.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Tunnel), nil
+	}
+}
+
+func (t tunnelDo) FirstOrCreate() (*model.Tunnel, error) {
+	if result, err := t.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Tunnel), nil
+	}
+}
+
+func (t tunnelDo) FindByPage(offset int, limit int) (result []*model.Tunnel, count int64, err error) {
+	result, err = t.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = t.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (t tunnelDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = t.Count()
+	if err != nil {
+		return
+	}
+
+	err = t.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (t tunnelDo) Scan(result interface{}) (err error) {
+	return t.DO.Scan(result)
+}
+
+func (t tunnelDo) Delete(models ...*model.Tunnel) (result gen.ResultInfo, err error) {
+	return t.DO.Delete(models)
+}
+
+func (t *tunnelDo) withDO(do gen.Dao) *tunnelDo {
+	t.DO = *do.(*gen.DO)
+	return t
+}
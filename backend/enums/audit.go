@@ -0,0 +1,18 @@
+package enums
+
+import "strings"
+
+type AuditRating string
+
+const (
+	AuditRatingOK         AuditRating = "OK"
+	AuditRatingDeprecated AuditRating = "Deprecated"
+	AuditRatingWeak       AuditRating = "Weak"
+	AuditRatingUnknown    AuditRating = "Unknown"
+)
+
+var AuditRatingEnums = []AuditRating{AuditRatingOK, AuditRatingDeprecated, AuditRatingWeak, AuditRatingUnknown}
+
+func (a AuditRating) TSName() string {
+	return strings.ToUpper(string(a))
+}
@@ -0,0 +1,48 @@
+package enums
+
+import "strings"
+
+type AuditAction string
+
+const (
+	AuditActionSessionOpen       AuditAction = "SessionOpen"
+	AuditActionSessionClose      AuditAction = "SessionClose"
+	AuditActionCredentialCreate  AuditAction = "CredentialCreate"
+	AuditActionCredentialUpdate  AuditAction = "CredentialUpdate"
+	AuditActionCredentialDelete  AuditAction = "CredentialDelete"
+	AuditActionCredentialDecrypt AuditAction = "CredentialDecrypt"
+	AuditActionHostKeyAccept     AuditAction = "HostKeyAccept"
+	AuditActionFileUpload        AuditAction = "FileUpload"
+	AuditActionFileDownload      AuditAction = "FileDownload"
+	AuditActionTunnelStart       AuditAction = "TunnelStart"
+)
+
+var AuditActionEnums = []AuditAction{
+	AuditActionSessionOpen,
+	AuditActionSessionClose,
+	AuditActionCredentialCreate,
+	AuditActionCredentialUpdate,
+	AuditActionCredentialDelete,
+	AuditActionCredentialDecrypt,
+	AuditActionHostKeyAccept,
+	AuditActionFileUpload,
+	AuditActionFileDownload,
+	AuditActionTunnelStart,
+}
+
+func (a AuditAction) TSName() string {
+	return strings.ToUpper(string(a))
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatJSON ExportFormat = "JSON"
+	ExportFormatCSV  ExportFormat = "CSV"
+)
+
+var ExportFormatEnums = []ExportFormat{ExportFormatJSON, ExportFormatCSV}
+
+func (e ExportFormat) TSName() string {
+	return strings.ToUpper(string(e))
+}
@@ -14,3 +14,16 @@ var AuthMethodEnums = []AuthMethod{Password, PrivateKey}
 func (a AuthMethod) TSName() string {
 	return strings.ToUpper(string(a))
 }
+
+type CredentialSource string
+
+const (
+	CredentialSourceLocal   CredentialSource = "Local"
+	CredentialSourceCommand CredentialSource = "Command"
+)
+
+var CredentialSourceEnums = []CredentialSource{CredentialSourceLocal, CredentialSourceCommand}
+
+func (c CredentialSource) TSName() string {
+	return strings.ToUpper(string(c))
+}
@@ -0,0 +1,18 @@
+package enums
+
+import "strings"
+
+type GroupDeleteMode string
+
+const (
+	// GroupDeleteModeReparent moves the subgroups and connections to the parent group.
+	GroupDeleteModeReparent GroupDeleteMode = "Reparent"
+	// GroupDeleteModeSubtree deletes the subgroups and their connections as well.
+	GroupDeleteModeSubtree GroupDeleteMode = "Subtree"
+)
+
+var GroupDeleteModeEnums = []GroupDeleteMode{GroupDeleteModeReparent, GroupDeleteModeSubtree}
+
+func (g GroupDeleteMode) TSName() string {
+	return strings.ToUpper(string(g))
+}
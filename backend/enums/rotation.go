@@ -0,0 +1,25 @@
+package enums
+
+import "strings"
+
+type RotationStatus string
+
+const (
+	RotationStatusVerified       RotationStatus = "Verified"
+	RotationStatusFailed         RotationStatus = "Failed"
+	RotationStatusRolledBack     RotationStatus = "RolledBack"
+	RotationStatusRollbackFailed RotationStatus = "RollbackFailed"
+	RotationStatusSkipped        RotationStatus = "Skipped"
+)
+
+var RotationStatusEnums = []RotationStatus{
+	RotationStatusVerified,
+	RotationStatusFailed,
+	RotationStatusRolledBack,
+	RotationStatusRollbackFailed,
+	RotationStatusSkipped,
+}
+
+func (r RotationStatus) TSName() string {
+	return strings.ToUpper(string(r))
+}
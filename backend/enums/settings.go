@@ -0,0 +1,17 @@
+package enums
+
+import "strings"
+
+type SettingSource string
+
+const (
+	SettingSourceConnection SettingSource = "Connection"
+	SettingSourceGroup      SettingSource = "Group"
+	SettingSourceDefault    SettingSource = "Default"
+)
+
+var SettingSourceEnums = []SettingSource{SettingSourceConnection, SettingSourceGroup, SettingSourceDefault}
+
+func (s SettingSource) TSName() string {
+	return strings.ToUpper(string(s))
+}
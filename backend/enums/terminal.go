@@ -11,9 +11,11 @@ const (
 	TerminalTypeFingerprintConfirm TerminalType = "FingerprintConfirm"
 	TerminalTypeResize             TerminalType = "Resize"
 	TerminalTypeCMD                TerminalType = "CMD"
+	TerminalTypeStats              TerminalType = "Stats"
+	TerminalTypeHostKeyChanged     TerminalType = "HostKeyChanged"
 )
 
-var TerminalTypeEnums = []TerminalType{TerminalTypeError, TerminalTypeData, TerminalTypeConnected, TerminalTypeFingerprintConfirm, TerminalTypeResize, TerminalTypeCMD}
+var TerminalTypeEnums = []TerminalType{TerminalTypeError, TerminalTypeData, TerminalTypeConnected, TerminalTypeFingerprintConfirm, TerminalTypeResize, TerminalTypeCMD, TerminalTypeStats, TerminalTypeHostKeyChanged}
 
 func (a TerminalType) TSName() string {
 	return strings.ToUpper(string(a))
@@ -0,0 +1,17 @@
+package enums
+
+import "strings"
+
+type TrashKind string
+
+const (
+	TrashKindConnection TrashKind = "Connection"
+	TrashKindCredential TrashKind = "Credential"
+	TrashKindGroup      TrashKind = "Group"
+)
+
+var TrashKindEnums = []TrashKind{TrashKindConnection, TrashKindCredential, TrashKindGroup}
+
+func (t TrashKind) TSName() string {
+	return strings.ToUpper(string(t))
+}
@@ -0,0 +1,32 @@
+package enums
+
+import "strings"
+
+type TunnelType string
+
+const (
+	TunnelTypeLocal   TunnelType = "Local"
+	TunnelTypeRemote  TunnelType = "Remote"
+	TunnelTypeDynamic TunnelType = "Dynamic"
+)
+
+var TunnelTypeEnums = []TunnelType{TunnelTypeLocal, TunnelTypeRemote, TunnelTypeDynamic}
+
+func (t TunnelType) TSName() string {
+	return strings.ToUpper(string(t))
+}
+
+type TunnelStatus string
+
+const (
+	TunnelStatusRunning    TunnelStatus = "Running"
+	TunnelStatusStopped    TunnelStatus = "Stopped"
+	TunnelStatusFailed     TunnelStatus = "Failed"
+	TunnelStatusRestarting TunnelStatus = "Restarting"
+)
+
+var TunnelStatusEnums = []TunnelStatus{TunnelStatusRunning, TunnelStatusStopped, TunnelStatusFailed, TunnelStatusRestarting}
+
+func (t TunnelStatus) TSName() string {
+	return strings.ToUpper(string(t))
+}
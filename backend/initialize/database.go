@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/Q191/GTerm/backend/consts"
-	"github.com/Q191/GTerm/backend/dal/model"
 	"github.com/Q191/GTerm/backend/dal/query"
 	"github.com/Q191/GTerm/backend/pkg/storage"
 	"github.com/glebarez/sqlite"
@@ -16,22 +15,25 @@ type Database struct {
 	Query *query.Query
 }
 
+// InitDatabase opens the database and migrates it to the latest schema version. An
+// existing database is backed up next to it before any migration runs.
 func InitDatabase() *query.Query {
 	database := &Database{}
 	localStorage := storage.NewLocalStorage(fmt.Sprintf("%s.%s", consts.ApplicationName, consts.DatabaseDriver))
 	if err := localStorage.CreateDirectory(); err != nil {
 		panic(err)
 	}
-	if !localStorage.DatabaseExist() {
-		if err := database.connect(localStorage.Path); err != nil {
-			panic(err)
-		}
-		if err := database.autoMigrate(); err != nil {
-			panic(err)
+	exist := localStorage.DatabaseExist()
+	if err := database.connect(localStorage.Path); err != nil {
+		panic(err)
+	}
+	var backup func(version int) error
+	if exist {
+		backup = func(version int) error {
+			return database.backup(backupPath(localStorage.Path, version))
 		}
-		return database.Query
 	}
-	if err := database.connect(localStorage.Path); err != nil {
+	if err := database.migrate(backup); err != nil {
 		panic(err)
 	}
 	return database.Query
@@ -46,13 +48,3 @@ func (d *Database) connect(dsn string) error {
 	d.Query = query.Use(d.db)
 	return nil
 }
-
-func (d *Database) autoMigrate() error {
-	models := []any{
-		model.Connection{},
-		model.Credential{},
-		model.Group{},
-		model.Metadata{},
-	}
-	return d.db.AutoMigrate(models...)
-}
@@ -0,0 +1,280 @@
+package initialize
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts"
+	"gorm.io/gorm"
+)
+
+// migration is one step of the database schema. Released steps must never be
+// changed or reordered; schema changes always go into a new step at the end, with
+// frozen structs from schema.go. Steps that alter columns must go through
+// rebuildTable.
+type migration struct {
+	version int
+	name    string
+	migrate func(tx *gorm.DB) error
+}
+
+var migrations = []migration{
+	{
+		// Databases created before versioning only ran AutoMigrate once, so this
+		// step brings them up to date as well as creating fresh databases.
+		version: 1,
+		name:    "initial schema",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&connectionV1{},
+				&credentialV1{},
+				&groupV1{},
+				&metadataV1{},
+				&securityAuditV1{},
+				&tunnelV1{},
+				&vaultV1{},
+				&auditLogV1{},
+			)
+		},
+	},
+	{
+		// group names are unique among their siblings instead of globally
+		version: 2,
+		name:    "nested groups",
+		migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&groupV2{}, &connectionV2{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&groupV1{}, "idx_groups_name") {
+				return tx.Migrator().DropIndex(&groupV1{}, "idx_groups_name")
+			}
+			return nil
+		},
+	},
+	{
+		// empty connection settings are inherited from groups, existing values stay
+		// set on the connection
+		version: 3,
+		name:    "group default settings",
+		migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&groupV3{}); err != nil {
+				return err
+			}
+			// the credential may now come from a group, and the theme and charset
+			// lose their column defaults
+			return rebuildTable(tx, "connections", func() error {
+				if err := tx.Migrator().AlterColumn(&connectionV3{}, "CredentialID"); err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&connectionV3{})
+			})
+		},
+	},
+	{
+		// tags, custom attributes and the full-text index used by connection search
+		version: 4,
+		name:    "connection search",
+		migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&connectionV4{}); err != nil {
+				return err
+			}
+			for _, stmt := range connectionSearchIndex {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Exec(indexConnectionRow("connections.deleted_at IS NULL")).Error
+		},
+	},
+	{
+		version: 5,
+		name:    "smart groups",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&groupV5{})
+		},
+	},
+	{
+		version: 6,
+		name:    "connection templates",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&connectionTemplateV6{})
+		},
+	},
+	{
+		version: 7,
+		name:    "session history",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&sessionHistoryV7{}, &preferencesV7{})
+		},
+	},
+	{
+		// deleted rows stay in the tables until purged from the trash, so the unique
+		// indexes only cover the live ones
+		version: 8,
+		name:    "trash",
+		migrate: func(tx *gorm.DB) error {
+			for _, index := range []struct {
+				model any
+				name  string
+			}{
+				{&connectionV1{}, "idx_connections_label"},
+				{&credentialV1{}, "idx_credentials_label"},
+				{&groupV2{}, "idx_groups_parent_name"},
+			} {
+				if tx.Migrator().HasIndex(index.model, index.name) {
+					if err := tx.Migrator().DropIndex(index.model, index.name); err != nil {
+						return err
+					}
+				}
+			}
+			if err := tx.AutoMigrate(&connectionV8{}, &credentialV8{}, &groupV8{},
+				&preferencesV8{}, &trashItemV8{}); err != nil {
+				return err
+			}
+			return tx.Model(&preferencesV8{}).Where("1 = 1").Update("trash_retention_days", 30).Error
+		},
+	},
+	{
+		// existing rows keep a NULL list, their non-empty settings stay overridden
+		version: 9,
+		name:    "connection overrides",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&connectionV9{})
+		},
+	},
+	{
+		version: 10,
+		name:    "proxy credentials",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&connectionV10{}, &groupV10{}, &connectionTemplateV10{})
+		},
+	},
+	{
+		// the audit never observes the negotiated algorithms, it only expects them
+		version: 11,
+		name:    "expected audit algorithms",
+		migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE `security_audits` RENAME COLUMN `negotiated` TO `expected`").Error; err != nil {
+				return err
+			}
+			return tx.Exec("UPDATE `security_audits` SET `findings` = replace(`findings`, '\"negotiated\":', '\"expected\":')").Error
+		},
+	},
+	{
+		version: 12,
+		name:    "tunnel socks credentials",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&tunnelV12{})
+		},
+	},
+}
+
+// rebuildTable runs alter, which may rebuild table. SQLite can only change a column
+// by copying the table, which drops its indexes and triggers, and the rename fails
+// while triggers of other tables refer to it. Every trigger, including the ones
+// keeping the search index in sync, is therefore dropped first, and the triggers and
+// the missing indexes of table are created again after.
+func rebuildTable(tx *gorm.DB, table string, alter func() error) error {
+	var objects []struct{ Type, Name, SQL string }
+	if err := tx.Raw("SELECT type, name, sql FROM sqlite_master WHERE sql IS NOT NULL AND (type = 'trigger' OR type = 'index' AND tbl_name = ?)",
+		table).Scan(&objects).Error; err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if object.Type != "trigger" {
+			continue
+		}
+		if err := tx.Exec(fmt.Sprintf("DROP TRIGGER `%s`", object.Name)).Error; err != nil {
+			return err
+		}
+	}
+	if err := alter(); err != nil {
+		return err
+	}
+	for _, object := range objects {
+		var count int64
+		if err := tx.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name = ?", object.Name).Scan(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Exec(object.SQL).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var ErrDatabaseTooNew = errors.New("database was created by a newer version")
+
+type schemaVersion struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (s *schemaVersion) TableName() string {
+	return "schema_version"
+}
+
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate runs every pending step in its own transaction. backup is called once
+// before the first step when there is anything to migrate.
+func (d *Database) migrate(backup func(version int) error) error {
+	if err := d.db.AutoMigrate(&schemaVersion{}); err != nil {
+		return err
+	}
+	current, err := d.schemaVersion()
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w: schema version %d, this version of %s supports up to %d, please update %s",
+			ErrDatabaseTooNew, current, consts.ApplicationName, latest, consts.ApplicationName)
+	}
+	if current == latest {
+		return nil
+	}
+
+	if backup != nil {
+		if err = backup(current); err != nil {
+			return fmt.Errorf("failed to back up database before migrating: %w", err)
+		}
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err = d.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaVersion{Version: m.version, Name: m.name, AppliedAt: time.Now()}).Error
+		}); err != nil {
+			return fmt.Errorf("failed to migrate database to version %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func (d *Database) schemaVersion() (int, error) {
+	var version int
+	err := d.db.Model(&schemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// backup copies the database with VACUUM INTO, which gives a consistent snapshot
+// even while the file is open.
+func (d *Database) backup(path string) error {
+	return d.db.Exec("VACUUM INTO ?", path).Error
+}
+
+func backupPath(dbPath string, version int) string {
+	return fmt.Sprintf("%s.v%d-%s.bak", dbPath, version, time.Now().Format("20060102-150405"))
+}
@@ -0,0 +1,366 @@
+package initialize
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The structs below freeze the tables as each migration step leaves them. Steps must
+// never use the structs from dal/model, which follow the latest schema, or an old
+// step would already create the columns and indexes of the later ones. A step that
+// only adds columns or indexes declares just those.
+
+type commonV1 struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type connectionV1 struct {
+	Common                 commonV1 `gorm:"embedded"`
+	Label                  string   `gorm:"uniqueIndex;not null"`
+	Host                   string
+	Port                   uint
+	SerialPort             string
+	ConnProtocol           string `gorm:"not null"`
+	CredentialID           *uint  `gorm:"not null"`
+	Credential             *credentialV1
+	UseCommonCredential    bool             `gorm:"not null"`
+	Metadata               *metadataV1      `gorm:"foreignKey:ConnectionID"`
+	SecurityAudit          *securityAuditV1 `gorm:"foreignKey:ConnectionID"`
+	GroupID                *uint
+	BaudRate               int
+	DataBits               int
+	StopBits               int
+	Parity                 int
+	Theme                  string `gorm:"not null,default:'Default'"`
+	SSHKeyExchanges        string `gorm:"type:json"`
+	SSHCiphers             string `gorm:"type:json"`
+	SSHMACs                string `gorm:"type:json"`
+	SSHPublicKeyAlgorithms string `gorm:"type:json"`
+	SSHHostKeyAlgorithms   string `gorm:"type:json"`
+	SSHCharset             string `gorm:"default:'UTF-8'"`
+	FallbackCredentialIDs  string `gorm:"type:json"`
+	TryDefaultIdentities   bool
+	MaxAuthTries           int
+	LastAuthIdentity       string
+	DebugTrace             bool
+}
+
+func (c *connectionV1) TableName() string {
+	return "connections"
+}
+
+type credentialV1 struct {
+	Common               commonV1 `gorm:"embedded"`
+	Label                string   `gorm:"uniqueIndex;not null"`
+	Username             string
+	IsCommonCredential   bool
+	AuthMethod           string
+	PasswordCiphertext   string
+	PasswordSalt         string
+	PrivateKeyCiphertext string
+	PrivateKeySalt       string
+	PassphraseCiphertext string
+	PassphraseSalt       string
+	Source               string
+	PasswordCommand      string
+	PrivateKeyCommand    string
+	PassphraseCommand    string
+	SecretCacheTTL       int
+}
+
+func (c *credentialV1) TableName() string {
+	return "credentials"
+}
+
+type groupV1 struct {
+	Common                commonV1 `gorm:"embedded"`
+	Name                  string   `gorm:"uniqueIndex;not null"`
+	FallbackCredentialIDs string   `gorm:"type:json"`
+	TryDefaultIdentities  bool
+}
+
+func (g *groupV1) TableName() string {
+	return "groups"
+}
+
+type metadataV1 struct {
+	Common       commonV1 `gorm:"embedded"`
+	ConnectionID uint     `gorm:"not null"`
+	Vendor       string   `gorm:"not null"`
+	Type         string   `gorm:"not null"`
+}
+
+func (m *metadataV1) TableName() string {
+	return "metadata"
+}
+
+type securityAuditV1 struct {
+	Common        commonV1 `gorm:"embedded"`
+	ConnectionID  uint     `gorm:"not null"`
+	ServerVersion string
+	Offered       string `gorm:"type:json"`
+	Negotiated    string `gorm:"type:json"`
+	Findings      string `gorm:"type:json"`
+	Rating        string
+}
+
+func (a *securityAuditV1) TableName() string {
+	return "security_audits"
+}
+
+type tunnelV1 struct {
+	Common       commonV1 `gorm:"embedded"`
+	Name         string   `gorm:"not null"`
+	ConnectionID uint     `gorm:"not null;index"`
+	Type         string   `gorm:"not null"`
+	Bind         string   `gorm:"not null"`
+	Target       string
+	RemoteDNS    bool
+	AutoStart    bool
+}
+
+func (t *tunnelV1) TableName() string {
+	return "tunnels"
+}
+
+type vaultV1 struct {
+	Common     commonV1 `gorm:"embedded"`
+	Salt       string   `gorm:"not null"`
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	WrappedKey string `gorm:"not null"`
+}
+
+func (v *vaultV1) TableName() string {
+	return "vaults"
+}
+
+type auditLogV1 struct {
+	ID           uint      `gorm:"primaryKey"`
+	CreatedAt    time.Time `gorm:"index"`
+	Action       string    `gorm:"not null;index"`
+	ConnectionID uint      `gorm:"index"`
+	CredentialID uint
+	Target       string
+	Detail       string
+	Size         int64
+}
+
+func (a *auditLogV1) TableName() string {
+	return "audit_logs"
+}
+
+type connectionV2 struct {
+	SortOrder int
+}
+
+func (c *connectionV2) TableName() string {
+	return "connections"
+}
+
+type groupV2 struct {
+	Name      string `gorm:"uniqueIndex:idx_groups_parent_name;not null"`
+	ParentID  *uint  `gorm:"uniqueIndex:idx_groups_parent_name"`
+	SortOrder int
+}
+
+func (g *groupV2) TableName() string {
+	return "groups"
+}
+
+// connectionSettingsV3 are the settings connections inherit from their groups.
+type connectionSettingsV3 struct {
+	Port                   uint
+	Theme                  string
+	SSHKeyExchanges        string `gorm:"type:json"`
+	SSHCiphers             string `gorm:"type:json"`
+	SSHMACs                string `gorm:"type:json"`
+	SSHPublicKeyAlgorithms string `gorm:"type:json"`
+	SSHHostKeyAlgorithms   string `gorm:"type:json"`
+	SSHCharset             string
+	JumpConnectionID       *uint
+	Proxy                  string
+	KeepAliveInterval      int
+}
+
+type connectionV3 struct {
+	CredentialID *uint
+	Settings     connectionSettingsV3 `gorm:"embedded"`
+}
+
+func (c *connectionV3) TableName() string {
+	return "connections"
+}
+
+type groupV3 struct {
+	CredentialID *uint
+	Settings     connectionSettingsV3 `gorm:"embedded"`
+}
+
+func (g *groupV3) TableName() string {
+	return "groups"
+}
+
+type connectionV4 struct {
+	Tags       string `gorm:"type:json"`
+	Attributes string `gorm:"type:json"`
+}
+
+func (c *connectionV4) TableName() string {
+	return "connections"
+}
+
+type groupV5 struct {
+	Rule string
+}
+
+func (g *groupV5) TableName() string {
+	return "groups"
+}
+
+type connectionTemplateV6 struct {
+	Common                commonV1 `gorm:"embedded"`
+	Name                  string   `gorm:"uniqueIndex;not null"`
+	LabelPattern          string
+	ConnProtocol          string `gorm:"not null"`
+	CredentialID          *uint
+	Credential            *credentialV1
+	UseCommonCredential   bool
+	GroupID               *uint
+	FallbackCredentialIDs string `gorm:"type:json"`
+	TryDefaultIdentities  bool
+	MaxAuthTries          int
+	DebugTrace            bool
+	Tags                  string               `gorm:"type:json"`
+	Attributes            string               `gorm:"type:json"`
+	Settings              connectionSettingsV3 `gorm:"embedded"`
+}
+
+func (t *connectionTemplateV6) TableName() string {
+	return "connection_templates"
+}
+
+type sessionHistoryV7 struct {
+	ID           uint `gorm:"primaryKey"`
+	ConnectionID uint `gorm:"index"`
+	Label        string
+	Host         string
+	Protocol     string
+	StartedAt    time.Time `gorm:"index"`
+	ConnectedAt  *time.Time
+	EndedAt      *time.Time
+	Duration     int64
+	ExitReason   string
+}
+
+func (h *sessionHistoryV7) TableName() string {
+	return "session_histories"
+}
+
+type preferencesV7 struct {
+	ID                   uint `gorm:"primaryKey"`
+	UpdatedAt            time.Time
+	HistoryRetentionDays int
+	HistoryLimit         int
+}
+
+func (p *preferencesV7) TableName() string {
+	return "preferences"
+}
+
+type connectionV8 struct {
+	Label string `gorm:"uniqueIndex:idx_connections_live_label,where:deleted_at IS NULL;not null"`
+}
+
+func (c *connectionV8) TableName() string {
+	return "connections"
+}
+
+type credentialV8 struct {
+	Label string `gorm:"uniqueIndex:idx_credentials_live_label,where:deleted_at IS NULL;not null"`
+}
+
+func (c *credentialV8) TableName() string {
+	return "credentials"
+}
+
+type groupV8 struct {
+	Name     string `gorm:"uniqueIndex:idx_groups_live_parent_name,where:deleted_at IS NULL;not null"`
+	ParentID *uint  `gorm:"uniqueIndex:idx_groups_live_parent_name,where:deleted_at IS NULL"`
+}
+
+func (g *groupV8) TableName() string {
+	return "groups"
+}
+
+type preferencesV8 struct {
+	TrashRetentionDays int
+}
+
+func (p *preferencesV8) TableName() string {
+	return "preferences"
+}
+
+type trashItemV8 struct {
+	ID               uint      `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index"`
+	Kind             string    `gorm:"not null"`
+	Name             string
+	ConnectionIDs    string `gorm:"type:json"`
+	CredentialIDs    string `gorm:"type:json"`
+	GroupIDs         string `gorm:"type:json"`
+	MetadataIDs      string `gorm:"type:json"`
+	SecurityAuditIDs string `gorm:"type:json"`
+	TunnelIDs        string `gorm:"type:json"`
+}
+
+func (t *trashItemV8) TableName() string {
+	return "trash_items"
+}
+
+type connectionV9 struct {
+	Overrides string `gorm:"type:json"`
+}
+
+func (c *connectionV9) TableName() string {
+	return "connections"
+}
+
+type connectionV10 struct {
+	ProxyCredentialID *uint
+}
+
+func (c *connectionV10) TableName() string {
+	return "connections"
+}
+
+type groupV10 struct {
+	ProxyCredentialID *uint
+}
+
+func (g *groupV10) TableName() string {
+	return "groups"
+}
+
+type connectionTemplateV10 struct {
+	ProxyCredentialID *uint
+}
+
+func (t *connectionTemplateV10) TableName() string {
+	return "connection_templates"
+}
+
+type tunnelV12 struct {
+	Username           string
+	PasswordCiphertext string
+	PasswordSalt       string
+}
+
+func (t *tunnelV12) TableName() string {
+	return "tunnels"
+}
@@ -0,0 +1,52 @@
+package initialize
+
+import "fmt"
+
+// ConnectionSearchTable is an FTS5 table with one row per live connection, keyed by
+// the connection ID. The trigram tokenizer lets it answer substring MATCH queries
+// and speeds up LIKE on its columns. Triggers keep it in sync with the connections
+// and metadata tables, so the services never write to it.
+const ConnectionSearchTable = "connections_fts"
+
+var connectionSearchIndex = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS connections_fts USING fts5(
+		label, host, protocol, tags, attributes, vendor, type,
+		tokenize = 'trigram'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS connections_fts_insert AFTER INSERT ON connections BEGIN
+		` + indexConnectionRow("connections.id = new.id AND connections.deleted_at IS NULL") + `;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS connections_fts_update AFTER UPDATE ON connections BEGIN
+		DELETE FROM connections_fts WHERE rowid = old.id;
+		` + indexConnectionRow("connections.id = new.id AND connections.deleted_at IS NULL") + `;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS connections_fts_delete AFTER DELETE ON connections BEGIN
+		DELETE FROM connections_fts WHERE rowid = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS metadata_fts_insert AFTER INSERT ON metadata BEGIN
+		DELETE FROM connections_fts WHERE rowid = new.connection_id;
+		` + indexConnectionRow("connections.id = new.connection_id AND connections.deleted_at IS NULL") + `;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS metadata_fts_update AFTER UPDATE ON metadata BEGIN
+		DELETE FROM connections_fts WHERE rowid IN (old.connection_id, new.connection_id);
+		` + indexConnectionRow("connections.id IN (old.connection_id, new.connection_id) AND connections.deleted_at IS NULL") + `;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS metadata_fts_delete AFTER DELETE ON metadata BEGIN
+		DELETE FROM connections_fts WHERE rowid = old.connection_id;
+		` + indexConnectionRow("connections.id = old.connection_id AND connections.deleted_at IS NULL") + `;
+	END`,
+	`DELETE FROM connections_fts`,
+}
+
+// indexConnectionRow returns the statement that (re)indexes the connections matching
+// where. Metadata is looked up per row because a connection may have several.
+func indexConnectionRow(where string) string {
+	return fmt.Sprintf(`INSERT INTO connections_fts (rowid, label, host, protocol, tags, attributes, vendor, type)
+		SELECT connections.id, connections.label, connections.host, connections.conn_protocol,
+			connections.tags, connections.attributes,
+			(SELECT vendor FROM metadata WHERE metadata.connection_id = connections.id
+				AND metadata.deleted_at IS NULL ORDER BY metadata.id DESC LIMIT 1),
+			(SELECT type FROM metadata WHERE metadata.connection_id = connections.id
+				AND metadata.deleted_at IS NULL ORDER BY metadata.id DESC LIMIT 1)
+		FROM connections WHERE %s`, where)
+}
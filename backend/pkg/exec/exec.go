@@ -45,6 +45,38 @@ func (a *Adapter) SetTimeout(timeout time.Duration) {
 }
 
 func (a *Adapter) Run(cmd string) *Result {
+	return a.run(func(session *ssh.Session) error {
+		return session.Run(cmd)
+	})
+}
+
+// RunWithInput runs cmd with input written to its standard input, for tools such as
+// passwd or chpasswd that read secrets from stdin.
+func (a *Adapter) RunWithInput(cmd, input string) *Result {
+	return a.run(func(session *ssh.Session) error {
+		session.Stdin = strings.NewReader(input)
+		return session.Run(cmd)
+	})
+}
+
+// RunShell feeds input to an interactive shell on a pseudo terminal. It is meant
+// for network devices whose CLI does not accept commands on the exec channel; the
+// input should end with the device's logout command.
+func (a *Adapter) RunShell(input string) *Result {
+	return a.run(func(session *ssh.Session) error {
+		modes := ssh.TerminalModes{ssh.ECHO: 0}
+		if err := session.RequestPty("vt100", 40, 200, modes); err != nil {
+			return err
+		}
+		session.Stdin = strings.NewReader(input)
+		if err := session.Shell(); err != nil {
+			return err
+		}
+		return session.Wait()
+	})
+}
+
+func (a *Adapter) run(start func(session *ssh.Session) error) *Result {
 	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
 	defer cancel()
 
@@ -62,7 +94,7 @@ func (a *Adapter) Run(cmd string) *Result {
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- session.Run(cmd)
+		errCh <- start(session)
 	}()
 
 	select {
@@ -0,0 +1,128 @@
+// Package hostrange expands host patterns such as 10.0.1.[1-40] or
+// sw-[a,b]-[01-04].example.com into the hosts they describe.
+package hostrange
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxHosts caps the number of hosts one expansion may produce.
+const MaxHosts = 1024
+
+var ErrTooManyHosts = fmt.Errorf("host range expands to more than %d hosts", MaxHosts)
+
+// Host is one expanded host. Parts holds the value chosen for each bracket, in order.
+type Host struct {
+	Name  string
+	Parts []string
+}
+
+// ExpandAll expands every whitespace separated pattern in text, keeping their order.
+func ExpandAll(text string) ([]*Host, error) {
+	var hosts []*Host
+	for _, pattern := range strings.Fields(text) {
+		expanded, err := Expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, expanded...)
+		if len(hosts) > MaxHosts {
+			return nil, ErrTooManyHosts
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no hosts given")
+	}
+	return hosts, nil
+}
+
+// Expand expands a single pattern. A bracket holds comma separated items, each a
+// numeric range a-b or a word. A range whose start has leading zeros pads every
+// number to that width, so [01-10] gives 01, 02 ... 10.
+func Expand(pattern string) ([]*Host, error) {
+	hosts := []*Host{{}}
+	rest := pattern
+	for rest != "" {
+		start := strings.IndexByte(rest, '[')
+		if start < 0 {
+			if strings.IndexByte(rest, ']') >= 0 {
+				return nil, fmt.Errorf("unbalanced ']' in %q", pattern)
+			}
+			appendText(hosts, rest)
+			break
+		}
+		if strings.IndexByte(rest[:start], ']') >= 0 {
+			return nil, fmt.Errorf("unbalanced ']' in %q", pattern)
+		}
+		end := strings.IndexByte(rest[start:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed '[' in %q", pattern)
+		}
+		end += start
+		appendText(hosts, rest[:start])
+
+		values, err := bracketValues(rest[start+1 : end])
+		if err != nil {
+			return nil, fmt.Errorf("%w in %q", err, pattern)
+		}
+		if len(hosts)*len(values) > MaxHosts {
+			return nil, ErrTooManyHosts
+		}
+		next := make([]*Host, 0, len(hosts)*len(values))
+		for _, host := range hosts {
+			for _, value := range values {
+				next = append(next, &Host{
+					Name:  host.Name + value,
+					Parts: append(append([]string(nil), host.Parts...), value),
+				})
+			}
+		}
+		hosts = next
+		rest = rest[end+1:]
+	}
+	if hosts[0].Name == "" {
+		return nil, errors.New("empty host pattern")
+	}
+	return hosts, nil
+}
+
+func appendText(hosts []*Host, text string) {
+	for _, host := range hosts {
+		host.Name += text
+	}
+}
+
+func bracketValues(body string) ([]string, error) {
+	var values []string
+	for _, item := range strings.Split(body, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, errors.New("empty item in range")
+		}
+		from, to, _ := strings.Cut(item, "-")
+		first, err1 := strconv.Atoi(from)
+		last, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil {
+			// a word, which may contain '-' itself
+			values = append(values, item)
+			continue
+		}
+		if first > last {
+			return nil, fmt.Errorf("range %q runs backwards", item)
+		}
+		if last-first >= MaxHosts {
+			return nil, ErrTooManyHosts
+		}
+		width := 0
+		if len(from) > 1 && from[0] == '0' {
+			width = len(from)
+		}
+		for n := first; n <= last; n++ {
+			values = append(values, fmt.Sprintf("%0*d", width, n))
+		}
+	}
+	return values, nil
+}
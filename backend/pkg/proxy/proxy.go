@@ -0,0 +1,193 @@
+// Package proxy opens TCP connections through socks5:// (local resolution),
+// socks5h:// (proxy resolution) and http:// CONNECT proxies, with optional
+// user:password in the URL.
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Dial connects to addr through the proxy at rawURL.
+func Dial(rawURL, addr string, timeout time.Duration) (net.Conn, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", rawURL, err)
+	}
+	conn, err := net.DialTimeout("tcp", proxyURL.Host, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxyURL.Host, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		err = socks5Connect(conn, proxyURL, addr)
+	case "http":
+		err = httpConnect(conn, proxyURL, addr)
+	default:
+		err = fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func socks5Connect(conn net.Conn, proxyURL *url.URL, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return err
+	}
+
+	methods := []byte{0x00}
+	if proxyURL.User != nil {
+		methods = []byte{0x02}
+	}
+	if _, err = conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 {
+		return errors.New("proxy is not a SOCKS5 server")
+	}
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		user := proxyURL.User.Username()
+		password, _ := proxyURL.User.Password()
+		if len(user) > 255 || len(password) > 255 {
+			return errors.New("proxy username or password is too long")
+		}
+		req := []byte{0x01, byte(len(user))}
+		req = append(req, user...)
+		req = append(req, byte(len(password)))
+		req = append(req, password...)
+		if _, err = conn.Write(req); err != nil {
+			return err
+		}
+		if _, err = io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return errors.New("proxy authentication failed")
+		}
+	default:
+		return errors.New("proxy requires an unsupported authentication method")
+	}
+
+	req := []byte{0x05, 0x01, 0x00}
+	ip := net.ParseIP(host)
+	if ip == nil && proxyURL.Scheme == "socks5" {
+		addrs, err := net.LookupIP(host)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return fmt.Errorf("no address found for %s", host)
+		}
+		ip = addrs[0]
+	}
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return errors.New("host name is too long")
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	case ip.To4() != nil:
+		req = append(req, 0x01)
+		req = append(req, ip.To4()...)
+	default:
+		req = append(req, 0x04)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err = conn.Write(req); err != nil {
+		return err
+	}
+
+	head := make([]byte, 4)
+	if _, err = io.ReadFull(conn, head); err != nil {
+		return err
+	}
+	if head[1] != 0x00 {
+		return fmt.Errorf("proxy refused to connect to %s, reply code %d", addr, head[1])
+	}
+	var skip int
+	switch head[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err = io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return errors.New("invalid proxy reply")
+	}
+	// bound address and port
+	_, err = io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
+
+func httpConnect(conn net.Conn, proxyURL *url.URL, addr string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+	// read the response byte by byte, the SSH server may send its version right
+	// after it and that must stay on the connection
+	var head []byte
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(head, []byte("\r\n\r\n")) {
+		if len(head) > 8192 {
+			return errors.New("proxy response header is too long")
+		}
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return err
+		}
+		head = append(head, b[0])
+	}
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(head)), req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused to connect to %s: %s", addr, res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package rotate
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Q191/GTerm/backend/pkg/exec"
+)
+
+// passwordAlphabet leaves out quotes, spaces and '?', which device CLIs treat specially.
+const passwordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789-_.+="
+
+const DefaultPasswordLength = 24
+
+var (
+	ErrUnsafePassword = errors.New("password may only contain letters, digits and - _ . + =")
+	ErrUnsafeLine     = errors.New("password cannot contain line breaks or control characters")
+	ErrInvalidUser    = errors.New("user name cannot be empty or contain spaces, quotes or control characters")
+)
+
+var bsdVendors = []string{"freebsd", "openbsd"}
+
+var unsupportedVendors = []string{"windows", "macos"}
+
+// Change describes how to change a password on one kind of host.
+type Change struct {
+	Command string
+	Input   string
+	// Shell feeds Input to an interactive shell instead of running Command.
+	Shell bool
+}
+
+func (c *Change) Run(adapter *exec.Adapter) error {
+	var result *exec.Result
+	if c.Shell {
+		result = adapter.RunShell(c.Input)
+	} else {
+		result = adapter.RunWithInput(c.Command, c.Input)
+	}
+	if c.Shell {
+		// interactive CLIs exit with arbitrary codes, success is decided by a fresh login
+		if err := result.Error(); err != nil && result.ExitCode() == 0 {
+			return err
+		}
+		return nil
+	}
+	if !result.Success() {
+		if err := result.Error(); err != nil {
+			return err
+		}
+		return fmt.Errorf("exit code %d: %s", result.ExitCode(), result.StdErr())
+	}
+	return nil
+}
+
+// ChangeFor picks the password change for a host by its detected vendor. Hosts with
+// no or an unrecognized vendor are treated as Unix servers. Passwords written into a
+// CLI command must only use the generated password alphabet, passwords answering a
+// prompt on their own line must not contain line breaks.
+func ChangeFor(vendor, user, oldPassword, newPassword string) (*Change, error) {
+	if err := validateUser(user); err != nil {
+		return nil, err
+	}
+	vendor = strings.ToLower(vendor)
+	switch vendor {
+	case "cisco", "arista", "huawei", "h3c", "mikrotik":
+		if err := ValidatePassword(newPassword); err != nil {
+			return nil, err
+		}
+	case "fortinet":
+		if err := ValidatePassword(newPassword); err != nil {
+			return nil, err
+		}
+		if err := ValidatePassword(oldPassword); err != nil {
+			return nil, err
+		}
+	default:
+		if err := validateLine(newPassword); err != nil {
+			return nil, err
+		}
+		if err := validateLine(oldPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	switch vendor {
+	case "cisco", "arista":
+		return &Change{Shell: true, Input: lines(
+			"configure terminal",
+			fmt.Sprintf("username %s secret %s", user, newPassword),
+			"end",
+			"write memory",
+			"exit",
+		)}, nil
+	case "huawei", "h3c":
+		return &Change{Shell: true, Input: lines(
+			"system-view",
+			"aaa",
+			fmt.Sprintf("local-user %s password irreversible-cipher %s", user, newPassword),
+			"quit",
+			"quit",
+			"save force",
+			"quit",
+		)}, nil
+	case "juniper":
+		return &Change{Shell: true, Input: lines(
+			"configure",
+			fmt.Sprintf("set system login user %s authentication plain-text-password", user),
+			newPassword,
+			newPassword,
+			"commit and-quit",
+			"exit",
+		)}, nil
+	case "fortinet":
+		return &Change{Shell: true, Input: lines(
+			"config system admin",
+			"edit "+user,
+			fmt.Sprintf("set password %s old %s", newPassword, oldPassword),
+			"end",
+			"exit",
+		)}, nil
+	case "mikrotik":
+		return &Change{Command: fmt.Sprintf(`/user set [find name="%s"] password="%s"`, user, newPassword)}, nil
+	}
+
+	if slices.Contains(unsupportedVendors, vendor) {
+		return nil, fmt.Errorf("password rotation is not supported on %s", vendor)
+	}
+	if slices.Contains(bsdVendors, vendor) {
+		if user != "root" {
+			return nil, fmt.Errorf("password rotation on %s requires the root account", vendor)
+		}
+		return &Change{Command: "pw usermod root -h 0", Input: lines(newPassword)}, nil
+	}
+	if user == "root" {
+		return &Change{Command: "chpasswd", Input: lines(user + ":" + newPassword)}, nil
+	}
+	return &Change{Command: "passwd", Input: lines(oldPassword, newPassword, newPassword)}, nil
+}
+
+func GeneratePassword(length int) (string, error) {
+	if length <= 0 {
+		length = DefaultPasswordLength
+	}
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// ValidatePassword checks that password only uses the alphabet of generated
+// passwords, which is safe to write into any supported device CLI.
+func ValidatePassword(password string) error {
+	if password == "" || strings.ContainsFunc(password, func(r rune) bool {
+		return !strings.ContainsRune(passwordAlphabet, r)
+	}) {
+		return ErrUnsafePassword
+	}
+	return nil
+}
+
+func validateLine(password string) error {
+	if strings.ContainsFunc(password, unicode.IsControl) {
+		return ErrUnsafeLine
+	}
+	return nil
+}
+
+func validateUser(user string) error {
+	if user == "" || strings.ContainsFunc(user, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r) || r == '"' || r == '\'' || r == '`'
+	}) {
+		return ErrInvalidUser
+	}
+	return nil
+}
+
+func lines(values ...string) string {
+	return strings.Join(values, "\n") + "\n"
+}
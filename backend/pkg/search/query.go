@@ -0,0 +1,223 @@
+// Package search parses the connection search syntax.
+//
+// A query is a list of terms joined by AND, which is implied between terms, and OR,
+// which binds looser: "tag:prod vendor:cisco OR host:10.*" means
+// (tag:prod AND vendor:cisco) OR host:10.*. A term is either free text or
+// field:value, may be negated with a leading '-' or NOT, and values may be quoted
+// to include spaces. '*' and '?' in values are wildcards.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	FieldLabel    = "label"
+	FieldHost     = "host"
+	FieldTag      = "tag"
+	FieldVendor   = "vendor"
+	FieldType     = "type"
+	FieldProtocol = "protocol"
+)
+
+var fields = []string{FieldLabel, FieldHost, FieldTag, FieldVendor, FieldType, FieldProtocol}
+
+var (
+	ErrEmptyQuery       = errors.New("empty search query")
+	ErrUnclosedQuote    = errors.New("unclosed quote in search query")
+	ErrMisplacedKeyword = errors.New("AND, OR and NOT must stand between terms")
+)
+
+// Term is a single condition. Field is empty for free text and is one of the Field
+// constants or, for anything else, the name of a custom attribute.
+type Term struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// Attribute reports whether the term matches a custom attribute.
+func (t Term) Attribute() bool {
+	for _, f := range fields {
+		if t.Field == f {
+			return false
+		}
+	}
+	return t.Field != ""
+}
+
+// Wildcard reports whether the value contains '*' or '?'.
+func (t Term) Wildcard() bool {
+	return strings.ContainsAny(t.Value, "*?")
+}
+
+func (t Term) String() string {
+	s := quote(t.Value)
+	if t.Field != "" {
+		s = t.Field + ":" + s
+	}
+	if t.Negate {
+		s = "-" + s
+	}
+	return s
+}
+
+// Query is an OR of clauses, each clause an AND of terms.
+type Query [][]Term
+
+func (q Query) String() string {
+	clauses := make([]string, len(q))
+	for i, c := range q {
+		terms := make([]string, len(c))
+		for j, t := range c {
+			terms[j] = t.String()
+		}
+		clauses[i] = strings.Join(terms, " ")
+	}
+	return strings.Join(clauses, " OR ")
+}
+
+// Parse parses a query. Field names are case-insensitive, the keywords AND, OR and
+// NOT must be upper case so they can still be searched for as words.
+func Parse(s string) (Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	var (
+		q      Query
+		clause []Term
+		negate bool
+		last   string
+	)
+	for _, tok := range tokens {
+		if !tok.quoted {
+			switch tok.text {
+			case "OR":
+				if len(clause) == 0 || negate {
+					return nil, ErrMisplacedKeyword
+				}
+				q = append(q, clause)
+				clause, last = nil, tok.text
+				continue
+			case "AND":
+				if len(clause) == 0 || negate {
+					return nil, ErrMisplacedKeyword
+				}
+				last = tok.text
+				continue
+			case "NOT":
+				negate, last = true, tok.text
+				continue
+			}
+		}
+		term := parseTerm(tok)
+		term.Negate = term.Negate != negate
+		clause = append(clause, term)
+		negate, last = false, ""
+	}
+	if last != "" {
+		return nil, ErrMisplacedKeyword
+	}
+	return append(q, clause), nil
+}
+
+type token struct {
+	text string
+	// quoted is set when any part was quoted, which turns keywords into plain words
+	quoted bool
+}
+
+func tokenize(s string) ([]token, error) {
+	var (
+		tokens []token
+		cur    strings.Builder
+		quoted bool
+		inWord bool
+		quote  rune
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, quoted, inWord = r, true, true
+		case unicode.IsSpace(r):
+			if inWord {
+				tokens = append(tokens, token{text: cur.String(), quoted: quoted})
+				cur.Reset()
+				quoted, inWord = false, false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, ErrUnclosedQuote
+	}
+	if inWord {
+		tokens = append(tokens, token{text: cur.String(), quoted: quoted})
+	}
+	return tokens, nil
+}
+
+func parseTerm(tok token) Term {
+	var term Term
+	text := tok.text
+	if strings.HasPrefix(text, "-") && len(text) > 1 {
+		term.Negate = true
+		text = text[1:]
+	}
+	if field, value, ok := strings.Cut(text, ":"); ok && field != "" && !strings.HasPrefix(value, ":") && validField(field) {
+		term.Field = strings.ToLower(field)
+		text = value
+	}
+	term.Value = text
+	return term
+}
+
+// validField keeps things like URLs typed as free text from being read as a field.
+// IPv6 addresses are caught by the "::" check in parseTerm.
+func validField(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsFunc(s, unicode.IsSpace) || strings.ContainsAny(s, `"':`) {
+		if strings.Contains(s, `"`) {
+			return "'" + s + "'"
+		}
+		return fmt.Sprintf(`"%s"`, s)
+	}
+	return s
+}
+
+// ValidateAttribute checks that a custom attribute name can be written as a field.
+func ValidateAttribute(name string) error {
+	if name == "" || !validField(name) {
+		return fmt.Errorf("attribute name %q may only contain letters, digits, '_', '-' and '.'", name)
+	}
+	for _, f := range fields {
+		if strings.EqualFold(name, f) {
+			return fmt.Errorf("attribute name %q is reserved for search", name)
+		}
+	}
+	return nil
+}
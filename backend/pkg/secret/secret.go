@@ -0,0 +1,120 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/initialize"
+)
+
+const (
+	DefaultCacheTTL = 5 * time.Minute
+	commandTimeout  = 30 * time.Second
+)
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Resolver runs secret commands of external password managers and keeps their
+// output in memory for a limited time. Nothing it resolves is ever persisted.
+type Resolver struct {
+	logger initialize.Logger
+	mu     sync.Mutex
+	cache  map[string]*entry
+}
+
+func NewResolver(logger initialize.Logger) *Resolver {
+	return &Resolver{
+		logger: logger,
+		cache:  make(map[string]*entry),
+	}
+}
+
+// Resolve returns the output of command, from the cache when still fresh. A
+// negative ttl disables caching, zero uses DefaultCacheTTL.
+func (r *Resolver) Resolve(command string, ttl time.Duration) (string, error) {
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[command]
+	if ok && time.Now().Before(cached.expiresAt) {
+		r.mu.Unlock()
+		return cached.value, nil
+	}
+	delete(r.cache, command)
+	r.mu.Unlock()
+
+	value, err := Run(command)
+	if err != nil {
+		return "", err
+	}
+	if ttl > 0 {
+		r.mu.Lock()
+		r.cache[command] = &entry{value: value, expiresAt: time.Now().Add(ttl)}
+		r.mu.Unlock()
+	}
+	return value, nil
+}
+
+func (r *Resolver) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.cache)
+	r.logger.Info("Secret cache cleared")
+}
+
+// Run executes command with the platform shell and returns its standard output.
+// The output itself is never logged.
+func Run(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("secret command is empty")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", errors.New("secret command timed out")
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %v", err)
+	}
+	if stdout.Len() == 0 {
+		return "", errors.New("secret command produced no output")
+	}
+	return stdout.String(), nil
+}
+
+// FirstLine extracts a password from command output. Tools such as pass print the
+// password on the first line followed by optional metadata.
+func FirstLine(output string) string {
+	line, _, _ := strings.Cut(output, "\n")
+	return strings.TrimRight(line, "\r")
+}
+
+// PrivateKey normalizes a PEM key printed by a command.
+func PrivateKey(output string) string {
+	return strings.TrimSpace(output) + "\n"
+}
@@ -0,0 +1,152 @@
+package ssh
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Q191/GTerm/backend/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// The lists below mirror what golang.org/x/crypto/ssh implements for the client
+// side. Legacy algorithms (diffie-hellman-group1-sha1, aes128-cbc, 3des-cbc,
+// ssh-rsa, ssh-dss ...) are supported but never offered unless configured.
+var (
+	supportedKeyExchanges = []string{
+		"curve25519-sha256", "curve25519-sha256@libssh.org",
+		"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+		"diffie-hellman-group14-sha256", "diffie-hellman-group16-sha512",
+		"diffie-hellman-group-exchange-sha256",
+		"diffie-hellman-group14-sha1", "diffie-hellman-group-exchange-sha1",
+		"diffie-hellman-group1-sha1",
+	}
+	defaultKeyExchanges = []string{
+		"curve25519-sha256", "curve25519-sha256@libssh.org",
+		"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+		"diffie-hellman-group14-sha256", "diffie-hellman-group14-sha1",
+	}
+
+	supportedCiphers = []string{
+		"aes128-gcm@openssh.com", "aes256-gcm@openssh.com",
+		"chacha20-poly1305@openssh.com",
+		"aes128-ctr", "aes192-ctr", "aes256-ctr",
+		"aes128-cbc", "3des-cbc",
+		"arcfour256", "arcfour128", "arcfour",
+	}
+	defaultCiphers = []string{
+		"aes128-gcm@openssh.com", "aes256-gcm@openssh.com",
+		"chacha20-poly1305@openssh.com",
+		"aes128-ctr", "aes192-ctr", "aes256-ctr",
+	}
+
+	supportedMACs = []string{
+		"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com",
+		"hmac-sha2-256", "hmac-sha2-512",
+		"hmac-sha1", "hmac-sha1-96",
+	}
+	defaultMACs = supportedMACs
+
+	supportedHostKeyAlgorithms = []string{
+		ssh.KeyAlgoED25519,
+		ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+		ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+		ssh.CertAlgoED25519v01,
+		ssh.CertAlgoECDSA256v01, ssh.CertAlgoECDSA384v01, ssh.CertAlgoECDSA521v01,
+		ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01,
+		ssh.KeyAlgoRSA, ssh.KeyAlgoDSA,
+		ssh.CertAlgoRSAv01, ssh.CertAlgoDSAv01,
+	}
+	defaultHostKeyAlgorithms = []string{
+		ssh.KeyAlgoED25519,
+		ssh.KeyAlgoECDSA256,
+		ssh.KeyAlgoECDSA384,
+		ssh.KeyAlgoECDSA521,
+		ssh.KeyAlgoRSASHA512,
+		ssh.KeyAlgoRSA,
+		ssh.KeyAlgoDSA,
+	}
+
+	supportedPublicKeyAlgorithms = []string{
+		ssh.KeyAlgoED25519,
+		ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+		ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256,
+		ssh.KeyAlgoSKED25519, ssh.KeyAlgoSKECDSA256,
+		ssh.KeyAlgoRSA, ssh.KeyAlgoDSA,
+	}
+	defaultPublicKeyAlgorithms = supportedPublicKeyAlgorithms
+)
+
+func SupportedAlgorithms() *types.SupportedSSHAlgorithms {
+	return &types.SupportedSSHAlgorithms{
+		Supported: &types.SSHAlgorithms{
+			KeyExchanges:        slices.Clone(supportedKeyExchanges),
+			Ciphers:             slices.Clone(supportedCiphers),
+			MACs:                slices.Clone(supportedMACs),
+			HostKeyAlgorithms:   slices.Clone(supportedHostKeyAlgorithms),
+			PublicKeyAlgorithms: slices.Clone(supportedPublicKeyAlgorithms),
+		},
+		Default: &types.SSHAlgorithms{
+			KeyExchanges:        slices.Clone(defaultKeyExchanges),
+			Ciphers:             slices.Clone(defaultCiphers),
+			MACs:                slices.Clone(defaultMACs),
+			HostKeyAlgorithms:   slices.Clone(defaultHostKeyAlgorithms),
+			PublicKeyAlgorithms: slices.Clone(defaultPublicKeyAlgorithms),
+		},
+	}
+}
+
+// ValidateAlgorithms rejects algorithm names the client cannot negotiate.
+func ValidateAlgorithms(algorithms *types.SSHAlgorithms) error {
+	checks := []struct {
+		kind      string
+		names     []string
+		supported []string
+	}{
+		{"key exchange", algorithms.KeyExchanges, supportedKeyExchanges},
+		{"cipher", algorithms.Ciphers, supportedCiphers},
+		{"MAC", algorithms.MACs, supportedMACs},
+		{"host key", algorithms.HostKeyAlgorithms, supportedHostKeyAlgorithms},
+		{"public key", algorithms.PublicKeyAlgorithms, supportedPublicKeyAlgorithms},
+	}
+	for _, check := range checks {
+		for _, name := range check.names {
+			if !slices.Contains(check.supported, name) {
+				return fmt.Errorf("unsupported %s algorithm: %s", check.kind, name)
+			}
+		}
+	}
+	return nil
+}
+
+// restrictSigner limits signer to the configured public key algorithms, reporting
+// false when none of them can be used with the key type.
+func restrictSigner(signer ssh.Signer, algorithms []string) (ssh.Signer, bool) {
+	if len(algorithms) == 0 {
+		return signer, true
+	}
+	keyType := signer.PublicKey().Type()
+	candidates := []string{keyType}
+	if keyType == ssh.KeyAlgoRSA {
+		candidates = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	}
+
+	var allowed []string
+	for _, algorithm := range algorithms {
+		if slices.Contains(candidates, algorithm) {
+			allowed = append(allowed, algorithm)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, false
+	}
+
+	algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+	if !ok {
+		return signer, true
+	}
+	restricted, err := ssh.NewSignerWithAlgorithms(algorithmSigner, allowed)
+	if err != nil {
+		return nil, false
+	}
+	return restricted, true
+}
@@ -0,0 +1,243 @@
+package ssh
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultMaxAuthTries stays below the OpenSSH server default (MaxAuthTries 6)
+// so that offering several identities does not get the client disconnected.
+const DefaultMaxAuthTries = 5
+
+// DefaultIdentityFiles mirrors the identity files ssh(1) tries by default, in order.
+var DefaultIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa", "id_dsa"}
+
+// errAuthBudgetExhausted aborts the handshake once no password is left to offer, as
+// x/crypto sends whatever a password callback returns. It counts as an
+// authentication failure, so the next user batch is still tried.
+var errAuthBudgetExhausted = errors.New("authentication attempts exhausted")
+
+// Identity is a single set of credentials offered to the server during authentication.
+type Identity struct {
+	Key        string
+	User       string
+	AuthMethod enums.AuthMethod
+	Password   string
+	PrivateKey string
+	Passphrase string
+}
+
+func userSSHDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".ssh")
+}
+
+func IdentityFileKey(path string) string {
+	return "file:" + path
+}
+
+func (c *Config) maxAuthTries() int {
+	if c.MaxAuthTries > 0 {
+		return c.MaxAuthTries
+	}
+	return DefaultMaxAuthTries
+}
+
+// identities returns the ordered identities to offer, falling back to the single
+// credential described by the legacy Config fields when no list is given.
+func (c *Config) identities(logger initialize.Logger) []*Identity {
+	identities := slices.Clone(c.Identities)
+	if len(identities) == 0 && c.AuthMethod != "" {
+		identities = append(identities, &Identity{
+			Key:        "config",
+			User:       c.User,
+			AuthMethod: c.AuthMethod,
+			Password:   c.Password,
+			PrivateKey: c.PrivateKey,
+			Passphrase: c.Passphrase,
+		})
+	}
+
+	if c.UseDefaultIdentities {
+		username := c.User
+		if username == "" && len(identities) > 0 {
+			username = identities[0].User
+		}
+		if username == "" {
+			if current, err := user.Current(); err == nil {
+				username = current.Username
+			}
+		}
+		identities = append(identities, loadDefaultIdentities(username, logger)...)
+	}
+
+	if c.PreferredIdentity != "" {
+		idx := slices.IndexFunc(identities, func(identity *Identity) bool {
+			return identity.Key == c.PreferredIdentity
+		})
+		if idx > 0 {
+			preferred := identities[idx]
+			identities = append([]*Identity{preferred}, slices.Delete(identities, idx, idx+1)...)
+			logger.Debug("Trying last successful identity first: %s", preferred.Key)
+		}
+	}
+	return identities
+}
+
+func loadDefaultIdentities(username string, logger initialize.Logger) []*Identity {
+	var identities []*Identity
+	for _, name := range DefaultIdentityFiles {
+		path := filepath.Join(userSSHDir(), name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		logger.Debug("Found default identity file: %s", path)
+		identities = append(identities, &Identity{
+			Key:        IdentityFileKey(path),
+			User:       username,
+			AuthMethod: enums.PrivateKey,
+			PrivateKey: string(data),
+		})
+	}
+	return identities
+}
+
+// groupIdentitiesByUser splits identities into per-user batches, preserving order.
+// Servers do not allow changing the user name within one handshake, so each batch
+// needs its own connection.
+func groupIdentitiesByUser(identities []*Identity) [][]*Identity {
+	var groups [][]*Identity
+	index := make(map[string]int)
+	for _, identity := range identities {
+		i, ok := index[identity.User]
+		if !ok {
+			i = len(groups)
+			index[identity.User] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], identity)
+	}
+	return groups
+}
+
+// authAttempt offers a batch of identities in a single handshake, counting every
+// attempt against a shared budget and remembering which identity was offered last.
+type authAttempt struct {
+	budget              int
+	used                int
+	current             *Identity
+	publicKeyAlgorithms []string
+	trace               *Trace
+}
+
+func newAuthAttempt(budget int, publicKeyAlgorithms []string, trace *Trace) *authAttempt {
+	return &authAttempt{budget: budget, publicKeyAlgorithms: publicKeyAlgorithms, trace: trace}
+}
+
+// next takes the next identity from queue, or returns false when the queue is empty
+// or the budget is used up.
+func (a *authAttempt) next(queue *[]*Identity, method string) (*Identity, bool) {
+	if len(*queue) == 0 || a.used >= a.budget {
+		return nil, false
+	}
+	if a.current != nil {
+		a.trace.Logf(TraceStageAuth, "Identity %s was rejected", a.current.Key)
+	}
+	identity := (*queue)[0]
+	*queue = (*queue)[1:]
+	a.used++
+	a.current = identity
+	a.trace.Logf(TraceStageAuth, "Attempt %d/%d: offering %s via %s", a.used, a.budget, identity.Key, method)
+	return identity, true
+}
+
+func (a *authAttempt) methods(identities []*Identity, logger initialize.Logger) []ssh.AuthMethod {
+	var keys, passwords []*Identity
+	signers := make(map[*Identity]ssh.Signer)
+	for _, identity := range identities {
+		switch identity.AuthMethod {
+		case enums.Password:
+			passwords = append(passwords, identity)
+		case enums.PrivateKey:
+			var signer ssh.Signer
+			var err error
+			if identity.Passphrase == "" {
+				signer, err = ssh.ParsePrivateKey([]byte(identity.PrivateKey))
+			} else {
+				signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(identity.PrivateKey), []byte(identity.Passphrase))
+			}
+			if err != nil {
+				logger.Warn("Skipping identity %s, failed to parse private key: %v", identity.Key, err)
+				a.trace.Logf(TraceStageAuth, "Skipping identity %s: %v", identity.Key, err)
+				continue
+			}
+			restricted, ok := restrictSigner(signer, a.publicKeyAlgorithms)
+			if !ok {
+				logger.Warn("Skipping identity %s, key type %s is not allowed by public key algorithms %v",
+					identity.Key, signer.PublicKey().Type(), a.publicKeyAlgorithms)
+				continue
+			}
+			signers[identity] = restricted
+			keys = append(keys, identity)
+		default:
+			logger.Warn("Skipping identity %s, unsupported authentication method: %s", identity.Key, identity.AuthMethod)
+		}
+	}
+
+	var auth []ssh.AuthMethod
+	if len(keys) > 0 {
+		queue := slices.Clone(keys)
+		auth = append(auth, ssh.RetryableAuthMethod(ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			identity, ok := a.next(&queue, "publickey")
+			if !ok {
+				// no signers fails the method without a request, leaving the
+				// password methods to the server
+				return nil, nil
+			}
+			logger.Info("Trying private key authentication, identity: %s", identity.Key)
+			return []ssh.Signer{signers[identity]}, nil
+		}), len(keys)))
+	}
+	if len(passwords) > 0 {
+		// password and keyboard-interactive share the queue, so a password rejected
+		// by one method is not offered again through the other
+		queue := slices.Clone(passwords)
+		auth = append(auth, ssh.RetryableAuthMethod(ssh.PasswordCallback(func() (string, error) {
+			identity, ok := a.next(&queue, "password")
+			if !ok {
+				return "", errAuthBudgetExhausted
+			}
+			logger.Info("Trying password authentication, identity: %s", identity.Key)
+			return identity.Password, nil
+		}), len(passwords)))
+
+		auth = append(auth, ssh.RetryableAuthMethod(ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			if len(questions) == 0 {
+				return answers, nil
+			}
+			identity, ok := a.next(&queue, "keyboard-interactive")
+			if !ok {
+				return nil, errAuthBudgetExhausted
+			}
+			logger.Info("Trying keyboard-interactive authentication, identity: %s", identity.Key)
+			if len(questions) == 1 {
+				answers[0] = identity.Password
+			}
+			return answers, nil
+		}), len(passwords)))
+	}
+	return auth
+}
+
+func isAuthError(err error) bool {
+	return err != nil && (errors.Is(err, errAuthBudgetExhausted) || strings.Contains(err.Error(), "unable to authenticate"))
+}
@@ -0,0 +1,131 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/proxy"
+	"golang.org/x/crypto/ssh"
+)
+
+// maxJumpDepth stops jump host chains that loop back on themselves.
+const maxJumpDepth = 8
+
+// dialTarget opens the transport to addr: through the jump host when one is set,
+// otherwise through the proxy, otherwise directly.
+func dialTarget(c *Config, addr string, timeout time.Duration, logger initialize.Logger) (net.Conn, error) {
+	if c.Jump != nil {
+		return dialJump(c, addr, logger)
+	}
+	if c.Proxy != "" {
+		return proxy.Dial(c.Proxy, addr, timeout)
+	}
+	return net.DialTimeout("tcp", addr, timeout)
+}
+
+// dialClient is ssh.Dial over dialTarget.
+func dialClient(c *Config, addr string, config *ssh.ClientConfig, logger initialize.Logger) (*ssh.Client, error) {
+	conn, err := dialTarget(c, addr, config.Timeout, logger)
+	if err != nil {
+		return nil, err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// hop returns the configuration of the host in the jump chain that addr refers to,
+// so host keys of jump hosts can be confirmed like the target's own.
+func (c *Config) hop(addr string) *Config {
+	for hop := c; hop != nil; hop = hop.Jump {
+		if fmt.Sprintf("%s:%d", hop.Host, hop.Port) == addr {
+			return hop
+		}
+	}
+	return c
+}
+
+// jumpConn closes the jump host client together with the tunnelled connection.
+type jumpConn struct {
+	net.Conn
+	client *ssh.Client
+}
+
+func (c *jumpConn) Close() error {
+	err := c.Conn.Close()
+	_ = c.client.Close()
+	return err
+}
+
+func dialJump(c *Config, addr string, logger initialize.Logger) (net.Conn, error) {
+	depth := 0
+	for jump := c.Jump; jump != nil; jump = jump.Jump {
+		if depth++; depth > maxJumpDepth {
+			return nil, fmt.Errorf("jump host chain is longer than %d hosts", maxJumpDepth)
+		}
+	}
+
+	logger.Info("Connecting to %s through jump host %s:%d", addr, c.Jump.Host, c.Jump.Port)
+	client, err := NewSSHClient(c.Jump, logger)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial("tcp", addr)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("jump host %s:%d failed to connect to %s: %w", c.Jump.Host, c.Jump.Port, addr, err)
+	}
+	return &jumpConn{Conn: conn, client: client}, nil
+}
+
+// keepAlive sends keepalive requests until the client is closed, and closes it
+// once the server stops answering. onReply, when set, receives the round trip time
+// of every answered request.
+func keepAlive(client *ssh.Client, interval time.Duration, onReply func(rtt time.Duration), logger initialize.Logger) {
+	done := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	missed := 0
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		replied := make(chan error, 1)
+		start := time.Now()
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			replied <- err
+		}()
+		var err error
+		select {
+		case err = <-replied:
+		case <-time.After(interval):
+			err = errors.New("keepalive timed out")
+		}
+		if err != nil {
+			if missed++; missed >= 3 {
+				logger.Warn("Server stopped answering keepalive requests, closing connection: %v", err)
+				_ = client.Close()
+				return
+			}
+			continue
+		}
+		missed = 0
+		if onReply != nil {
+			onReply(time.Since(start))
+		}
+	}
+}
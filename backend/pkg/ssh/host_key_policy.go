@@ -0,0 +1,36 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/skeema/knownhosts"
+	"golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+// hostKeyPolicy is the host key verification used by every client built from a
+// Config. Only keys pinned in known_hosts are accepted; unknown and changed keys
+// fail the handshake and are turned into confirmation errors by handleDialError.
+// A known_hosts file that exists but cannot be read refuses the connection.
+func hostKeyPolicy(host string, logger initialize.Logger) (ssh.HostKeyCallback, []string, error) {
+	knownHostsFile := KnownHostsFile()
+	logger.Debug("Using known_hosts file: %s", knownHostsFile)
+
+	db, err := knownhosts.NewDB(knownHostsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Info("known_hosts file does not exist yet, every host is unknown")
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return &xknownhosts.KeyError{}
+		}, nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to load known_hosts database: %v", err)
+		return nil, nil, fmt.Errorf("failed to load known_hosts: %w", err)
+	}
+	logger.Info("Using known_hosts for host key verification")
+	return db.HostKeyCallback(), db.HostKeyAlgorithms(host), nil
+}
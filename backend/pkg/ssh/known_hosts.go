@@ -0,0 +1,320 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/skeema/knownhosts"
+	"golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	HostKeyScanPinned    = "pinned"
+	HostKeyScanUnknown   = "unknown"
+	HostKeyScanUnchanged = "unchanged"
+	HostKeyScanChanged   = "changed"
+	HostKeyScanFailed    = "failed"
+)
+
+func KnownHostsFile() string {
+	return filepath.Join(userSSHDir(), "known_hosts")
+}
+
+// ListKnownHosts parses the known_hosts file. When query is not empty only entries
+// whose host patterns contain it are returned; hashed entries match when query is
+// exactly the host (optionally with port) they were hashed from.
+func ListKnownHosts(query string) ([]*types.KnownHostEntry, error) {
+	lines, err := readKnownHosts()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*types.KnownHostEntry, 0)
+	for i, line := range lines {
+		entry := parseKnownHostLine(i+1, line)
+		if entry == nil {
+			continue
+		}
+		if query != "" && !entryMatchesQuery(entry, query) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// RemoveKnownHost removes host:port from every entry listing it, including hashed
+// ones, and returns the number of entries changed. Other hosts sharing a line with
+// it stay pinned. @revoked and @cert-authority lines are left alone, re-pinning a
+// host must not revoke or trust anything else.
+func RemoveKnownHost(host string, port uint) (int, error) {
+	address := knownhosts.Normalize(fmt.Sprintf("%s:%d", host, port))
+	return rewriteKnownHosts(func(entry *types.KnownHostEntry) []string {
+		if entry.Marker != "" {
+			return entry.Hosts
+		}
+		return slices.DeleteFunc(slices.Clone(entry.Hosts), func(pattern string) bool {
+			return hostMatchesAddress(pattern, address)
+		})
+	})
+}
+
+// RemoveKnownHostLine removes the entry on the given 1-based line number.
+func RemoveKnownHostLine(line int) (int, error) {
+	return rewriteKnownHosts(func(entry *types.KnownHostEntry) []string {
+		if entry.Line == line {
+			return nil
+		}
+		return entry.Hosts
+	})
+}
+
+// ScanHostKey fetches the host key offered by the server without authenticating
+// and compares it against known_hosts. It returns the fingerprint together with
+// HostKeyScanUnknown, HostKeyScanChanged or HostKeyScanUnchanged.
+func ScanHostKey(c *Config, logger initialize.Logger) (string, string, error) {
+	key, err := getHostKey(c, logger)
+	if err != nil {
+		return "", HostKeyScanFailed, err
+	}
+	status, err := knownHostStatus(c, key)
+	return ssh.FingerprintSHA256(key), status, err
+}
+
+// PinHostKey compares the server key against known_hosts and appends it when the
+// host is unknown. Changed keys are reported but never replaced here.
+func PinHostKey(c *Config, logger initialize.Logger) (string, string, error) {
+	key, err := getHostKey(c, logger)
+	if err != nil {
+		return "", HostKeyScanFailed, err
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+	status, err := knownHostStatus(c, key)
+	if status != HostKeyScanUnknown {
+		return fingerprint, status, err
+	}
+	if err = appendKnownHost(fmt.Sprintf("%s:%d", c.Host, c.Port), key, logger); err != nil {
+		return fingerprint, HostKeyScanFailed, err
+	}
+	return fingerprint, HostKeyScanPinned, nil
+}
+
+func knownHostStatus(c *Config, key ssh.PublicKey) (string, error) {
+	db, err := knownhosts.NewDB(KnownHostsFile())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return HostKeyScanUnknown, nil
+		}
+		return HostKeyScanFailed, err
+	}
+	// the remote address is only consulted when no hostname is given
+	err = db.HostKeyCallback()(fmt.Sprintf("%s:%d", c.Host, c.Port), &net.TCPAddr{}, key)
+	switch {
+	case err == nil:
+		return HostKeyScanUnchanged, nil
+	case knownhosts.IsHostKeyChanged(err):
+		return HostKeyScanChanged, nil
+	case knownhosts.IsHostUnknown(err):
+		return HostKeyScanUnknown, nil
+	}
+	return HostKeyScanFailed, err
+}
+
+// ReplaceFingerprint drops the keys pinned for the host and pins the current key,
+// provided it still matches the fingerprint the user confirmed.
+func ReplaceFingerprint(conf *Config, host, fingerprint string, logger initialize.Logger) error {
+	logger.Info("Replacing host fingerprint, host: %s, fingerprint: %s", host, fingerprint)
+	conf = conf.hop(host)
+	key, err := getHostKey(conf, logger)
+	if err != nil {
+		return err
+	}
+	if actual := ssh.FingerprintSHA256(key); actual != fingerprint {
+		logger.Error("Fingerprint mismatch, expected: %s, actual: %s", fingerprint, actual)
+		return errors.New("fingerprint mismatch")
+	}
+	removed, err := RemoveKnownHost(conf.Host, conf.Port)
+	if err != nil {
+		return err
+	}
+	logger.Info("Removed %d old known_hosts entries for %s", removed, host)
+	return appendKnownHost(host, key, logger)
+}
+
+func appendKnownHost(host string, key ssh.PublicKey, logger initialize.Logger) error {
+	if err := os.MkdirAll(userSSHDir(), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(KnownHostsFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		logger.Error("Failed to open known_hosts file: %v", err)
+		return err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	if err = knownhosts.WriteKnownHost(f, host, &net.TCPAddr{IP: net.IPv4zero}, key); err != nil {
+		logger.Error("Failed to write to known_hosts file: %v", err)
+		return err
+	}
+	logger.Info("Successfully added fingerprint to known_hosts, fingerprint: %s", ssh.FingerprintSHA256(key))
+	return nil
+}
+
+func readKnownHosts() ([]string, error) {
+	data, err := os.ReadFile(KnownHostsFile())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// rewriteKnownHosts lets keep pick the host patterns each entry keeps and drops the
+// entries left without any. It returns the number of entries changed.
+func rewriteKnownHosts(keep func(entry *types.KnownHostEntry) []string) (int, error) {
+	lines, err := readKnownHosts()
+	if err != nil {
+		return 0, err
+	}
+	var kept []string
+	removed := 0
+	for i, line := range lines {
+		entry := parseKnownHostLine(i+1, line)
+		if entry == nil {
+			kept = append(kept, line)
+			continue
+		}
+		hosts := keep(entry)
+		switch {
+		case len(hosts) == 0:
+			removed++
+		case len(hosts) < len(entry.Hosts):
+			removed++
+			kept = append(kept, replaceKnownHostPatterns(line, hosts))
+		default:
+			kept = append(kept, line)
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	path := KnownHostsFile()
+	tmp := path + ".tmp"
+	content := strings.Join(kept, "\n")
+	if len(kept) > 0 {
+		content += "\n"
+	}
+	if err = os.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	return removed, nil
+}
+
+// replaceKnownHostPatterns swaps the host field of a valid known_hosts line, leaving
+// the marker, key and comment as they were.
+func replaceKnownHostPatterns(line string, hosts []string) string {
+	rest := strings.TrimSpace(line)
+	var marker string
+	if strings.HasPrefix(rest, "@") {
+		i := strings.IndexAny(rest, " \t")
+		marker, rest = rest[:i+1], strings.TrimLeft(rest[i:], " \t")
+	}
+	i := strings.IndexAny(rest, " \t")
+	return marker + strings.Join(hosts, ",") + rest[i:]
+}
+
+func parseKnownHostLine(number int, line string) *types.KnownHostEntry {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return nil
+	}
+	marker, hosts, key, comment, _, err := ssh.ParseKnownHosts([]byte(trimmed))
+	if err != nil {
+		return nil
+	}
+	return &types.KnownHostEntry{
+		Line:        number,
+		Marker:      marker,
+		Hosts:       hosts,
+		Hashed:      len(hosts) > 0 && strings.HasPrefix(hosts[0], "|1|"),
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+		Comment:     comment,
+	}
+}
+
+func entryMatchesQuery(entry *types.KnownHostEntry, query string) bool {
+	if strings.Contains(entry.Fingerprint, query) {
+		return true
+	}
+	for _, host := range entry.Hosts {
+		if !strings.HasPrefix(host, "|1|") && strings.Contains(host, query) {
+			return true
+		}
+	}
+	address := query
+	if !strings.Contains(query, ":") || strings.HasPrefix(query, "[") {
+		address = knownhosts.Normalize(query)
+	}
+	return entryMatchesAddress(entry, address)
+}
+
+// entryMatchesAddress reports whether entry lists the normalized address, either
+// verbatim or as a hashed hostname.
+func entryMatchesAddress(entry *types.KnownHostEntry, address string) bool {
+	return slices.ContainsFunc(entry.Hosts, func(host string) bool {
+		return hostMatchesAddress(host, address)
+	})
+}
+
+func hostMatchesAddress(host, address string) bool {
+	if strings.HasPrefix(host, "|1|") {
+		return hashedHostMatches(host, address)
+	}
+	return xknownhosts.Normalize(host) == address || host == address
+}
+
+func hashedHostMatches(encoded, address string) bool {
+	parts := strings.Split(encoded, "|")
+	if len(parts) != 4 || parts[1] != "1" {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(address))
+	return hmac.Equal(mac.Sum(nil), hash)
+}
@@ -0,0 +1,175 @@
+package ssh
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/types"
+)
+
+const (
+	AuditCategoryVersion     = "version"
+	AuditCategoryKeyExchange = "kex"
+	AuditCategoryHostKey     = "hostKey"
+	AuditCategoryCipher      = "cipher"
+	AuditCategoryMAC         = "mac"
+)
+
+type policyRule struct {
+	rating enums.AuditRating
+	reason string
+}
+
+// auditPolicy is the built-in rating of algorithm names, loosely following ssh-audit.
+// Names are matched exactly first and then by prefix (entries ending in "*").
+var auditPolicy = map[string]map[string]policyRule{
+	AuditCategoryKeyExchange: {
+		"diffie-hellman-group1-sha1":           {enums.AuditRatingWeak, "1024-bit modulus and SHA-1"},
+		"diffie-hellman-group-exchange-sha1":   {enums.AuditRatingWeak, "uses SHA-1"},
+		"rsa1024-sha1":                         {enums.AuditRatingWeak, "1024-bit RSA and SHA-1"},
+		"gss-*":                                {enums.AuditRatingWeak, "GSSAPI key exchange with SHA-1"},
+		"diffie-hellman-group14-sha1":          {enums.AuditRatingDeprecated, "uses SHA-1"},
+		"rsa2048-sha256":                       {enums.AuditRatingDeprecated, "RSA key exchange is discouraged"},
+		"ecdh-sha2-nistp256":                   {enums.AuditRatingDeprecated, "NIST P-curves are possibly backdoored"},
+		"ecdh-sha2-nistp384":                   {enums.AuditRatingDeprecated, "NIST P-curves are possibly backdoored"},
+		"ecdh-sha2-nistp521":                   {enums.AuditRatingDeprecated, "NIST P-curves are possibly backdoored"},
+		"curve25519-sha256":                    {enums.AuditRatingOK, ""},
+		"curve25519-sha256@libssh.org":         {enums.AuditRatingOK, ""},
+		"diffie-hellman-group14-sha256":        {enums.AuditRatingOK, ""},
+		"diffie-hellman-group16-sha512":        {enums.AuditRatingOK, ""},
+		"diffie-hellman-group18-sha512":        {enums.AuditRatingOK, ""},
+		"diffie-hellman-group-exchange-sha256": {enums.AuditRatingOK, ""},
+		"sntrup761x25519-sha512":               {enums.AuditRatingOK, ""},
+		"sntrup761x25519-sha512@openssh.com":   {enums.AuditRatingOK, ""},
+		"mlkem768x25519-sha256":                {enums.AuditRatingOK, ""},
+	},
+	AuditCategoryHostKey: {
+		"ssh-dss":                             {enums.AuditRatingWeak, "1024-bit DSA"},
+		"ssh-dss-cert-v01@openssh.com":        {enums.AuditRatingWeak, "1024-bit DSA"},
+		"ssh-rsa":                             {enums.AuditRatingWeak, "SHA-1 signatures"},
+		"ssh-rsa-cert-v01@openssh.com":        {enums.AuditRatingWeak, "SHA-1 signatures"},
+		"ecdsa-sha2-nistp*":                   {enums.AuditRatingDeprecated, "NIST P-curves are possibly backdoored"},
+		"ssh-ed25519":                         {enums.AuditRatingOK, ""},
+		"ssh-ed25519-cert-v01@openssh.com":    {enums.AuditRatingOK, ""},
+		"rsa-sha2-256":                        {enums.AuditRatingOK, ""},
+		"rsa-sha2-512":                        {enums.AuditRatingOK, ""},
+		"rsa-sha2-256-cert-v01@openssh.com":   {enums.AuditRatingOK, ""},
+		"rsa-sha2-512-cert-v01@openssh.com":   {enums.AuditRatingOK, ""},
+		"sk-ssh-ed25519@openssh.com":          {enums.AuditRatingOK, ""},
+		"sk-ecdsa-sha2-nistp256@openssh.com":  {enums.AuditRatingDeprecated, "NIST P-curves are possibly backdoored"},
+		"sk-ssh-ed25519-cert-v01@openssh.com": {enums.AuditRatingOK, ""},
+	},
+	AuditCategoryCipher: {
+		"none":                          {enums.AuditRatingWeak, "no encryption"},
+		"des-cbc":                       {enums.AuditRatingWeak, "broken cipher"},
+		"3des-cbc":                      {enums.AuditRatingWeak, "64-bit block size (Sweet32)"},
+		"blowfish-cbc":                  {enums.AuditRatingWeak, "64-bit block size (Sweet32)"},
+		"cast128-cbc":                   {enums.AuditRatingWeak, "64-bit block size (Sweet32)"},
+		"arcfour*":                      {enums.AuditRatingWeak, "broken RC4 cipher"},
+		"aes128-cbc":                    {enums.AuditRatingDeprecated, "CBC mode is vulnerable to plaintext recovery"},
+		"aes192-cbc":                    {enums.AuditRatingDeprecated, "CBC mode is vulnerable to plaintext recovery"},
+		"aes256-cbc":                    {enums.AuditRatingDeprecated, "CBC mode is vulnerable to plaintext recovery"},
+		"rijndael-cbc@lysator.liu.se":   {enums.AuditRatingDeprecated, "CBC mode is vulnerable to plaintext recovery"},
+		"aes128-ctr":                    {enums.AuditRatingOK, ""},
+		"aes192-ctr":                    {enums.AuditRatingOK, ""},
+		"aes256-ctr":                    {enums.AuditRatingOK, ""},
+		"aes128-gcm@openssh.com":        {enums.AuditRatingOK, ""},
+		"aes256-gcm@openssh.com":        {enums.AuditRatingOK, ""},
+		"chacha20-poly1305@openssh.com": {enums.AuditRatingOK, ""},
+	},
+	AuditCategoryMAC: {
+		"none":                          {enums.AuditRatingWeak, "no integrity protection"},
+		"hmac-md5*":                     {enums.AuditRatingWeak, "broken MD5 hash"},
+		"hmac-sha1-96*":                 {enums.AuditRatingWeak, "SHA-1 with truncated tag"},
+		"umac-64*":                      {enums.AuditRatingDeprecated, "small 64-bit tag"},
+		"hmac-ripemd160*":               {enums.AuditRatingDeprecated, "legacy hash"},
+		"hmac-sha1":                     {enums.AuditRatingDeprecated, "SHA-1 in encrypt-and-MAC mode"},
+		"hmac-sha1-etm@openssh.com":     {enums.AuditRatingDeprecated, "uses SHA-1"},
+		"hmac-sha2-256":                 {enums.AuditRatingDeprecated, "encrypt-and-MAC mode"},
+		"hmac-sha2-512":                 {enums.AuditRatingDeprecated, "encrypt-and-MAC mode"},
+		"umac-128@openssh.com":          {enums.AuditRatingDeprecated, "encrypt-and-MAC mode"},
+		"hmac-sha2-256-etm@openssh.com": {enums.AuditRatingOK, ""},
+		"hmac-sha2-512-etm@openssh.com": {enums.AuditRatingOK, ""},
+		"umac-128-etm@openssh.com":      {enums.AuditRatingOK, ""},
+	},
+}
+
+// pseudoAlgorithms are extension markers advertised in the algorithm lists.
+var pseudoAlgorithms = []string{"ext-info-s", "ext-info-c", "kex-strict-s-v00@openssh.com", "kex-strict-c-v00@openssh.com"}
+
+func RateAlgorithm(category, name string) (enums.AuditRating, string) {
+	rules := auditPolicy[category]
+	if rule, ok := rules[name]; ok {
+		return rule.rating, rule.reason
+	}
+	for pattern, rule := range rules {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(name, prefix) {
+			return rule.rating, rule.reason
+		}
+	}
+	return enums.AuditRatingUnknown, "not covered by the built-in policy"
+}
+
+// AuditProbe rates everything the server offered and returns the findings
+// together with the worst rating among them.
+func AuditProbe(probe *types.SSHServerProbe) ([]*types.SSHAuditFinding, enums.AuditRating) {
+	var findings []*types.SSHAuditFinding
+	worst := enums.AuditRatingOK
+
+	add := func(finding *types.SSHAuditFinding) {
+		findings = append(findings, finding)
+		if ratingSeverity(finding.Rating) > ratingSeverity(worst) {
+			worst = finding.Rating
+		}
+	}
+
+	if strings.HasPrefix(probe.ServerVersion, "SSH-1.") {
+		add(&types.SSHAuditFinding{
+			Category:  AuditCategoryVersion,
+			Algorithm: probe.ServerVersion,
+			Rating:    enums.AuditRatingWeak,
+			Reason:    "server still accepts SSH protocol 1",
+		})
+	}
+
+	categories := []struct {
+		category string
+		names    []string
+		expected []string
+	}{
+		{AuditCategoryKeyExchange, probe.Offered.KeyExchanges, []string{probe.Expected.KeyExchange}},
+		{AuditCategoryHostKey, probe.Offered.HostKeyAlgorithms, []string{probe.Expected.HostKeyAlgorithm}},
+		{AuditCategoryCipher, probe.Offered.Ciphers, []string{probe.Expected.CipherClientToServer, probe.Expected.CipherServerToClient}},
+		{AuditCategoryMAC, probe.Offered.MACs, []string{probe.Expected.MACClientToServer, probe.Expected.MACServerToClient}},
+	}
+	for _, c := range categories {
+		for _, name := range c.names {
+			if slices.Contains(pseudoAlgorithms, name) {
+				continue
+			}
+			rating, reason := RateAlgorithm(c.category, name)
+			add(&types.SSHAuditFinding{
+				Category:  c.category,
+				Algorithm: name,
+				Rating:    rating,
+				Reason:    reason,
+				Expected:  slices.Contains(c.expected, name),
+			})
+		}
+	}
+	return findings, worst
+}
+
+func ratingSeverity(rating enums.AuditRating) int {
+	switch rating {
+	case enums.AuditRatingWeak:
+		return 3
+	case enums.AuditRatingDeprecated:
+		return 2
+	case enums.AuditRatingUnknown:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,179 @@
+package ssh
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+)
+
+const (
+	probeClientVersion = "SSH-2.0-GTerm_Audit"
+	msgKexInit         = 20
+	maxPacketLength    = 256 * 1024
+	maxBannerLines     = 64
+)
+
+// ProbeServer performs the unencrypted part of the handshake: it exchanges version
+// strings, reads the server KEXINIT and works out which algorithms the client would
+// negotiate against it with the configured (or default) preference lists. The probe
+// stops before key exchange, so these are only expected, not observed on the wire.
+func ProbeServer(c *Config, logger initialize.Logger) (*types.SSHServerProbe, error) {
+	host := net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port)))
+	timeout := 10 * time.Second
+	if c.Timeout > 0 {
+		timeout = c.Timeout
+	}
+
+	logger.Info("Probing SSH server %s", host)
+	conn, err := dialTarget(c, host, timeout, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err = fmt.Fprintf(conn, "%s\r\n", probeClientVersion); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	version, err := readServerVersion(reader)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Server version: %s", version)
+
+	payload, err := readPacket(reader)
+	if err != nil {
+		return nil, err
+	}
+	offered, err := parseKexInit(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SSHServerProbe{
+		ServerVersion: version,
+		Offered:       offered,
+		Expected:      expectedAlgorithms(c, offered),
+	}, nil
+}
+
+func readServerVersion(reader *bufio.Reader) (string, error) {
+	for i := 0; i < maxBannerLines; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "SSH-") {
+			return line, nil
+		}
+	}
+	return "", errors.New("server did not send an SSH version string")
+}
+
+func readPacket(reader *bufio.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length < padding+1 || length > maxPacketLength {
+		return nil, fmt.Errorf("invalid packet length: %d", length)
+	}
+	body := make([]byte, length-1)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+	return body[:len(body)-int(padding)], nil
+}
+
+func parseKexInit(payload []byte) (*types.SSHAlgorithms, error) {
+	if len(payload) < 17 || payload[0] != msgKexInit {
+		return nil, errors.New("server did not send KEXINIT")
+	}
+	rest := payload[17:]
+	lists := make([][]string, 6)
+	for i := range lists {
+		if len(rest) < 4 {
+			return nil, errors.New("truncated KEXINIT")
+		}
+		n := binary.BigEndian.Uint32(rest[:4])
+		rest = rest[4:]
+		if uint32(len(rest)) < n {
+			return nil, errors.New("truncated KEXINIT")
+		}
+		if n > 0 {
+			lists[i] = strings.Split(string(rest[:n]), ",")
+		}
+		rest = rest[n:]
+	}
+
+	// the server-to-client cipher and MAC lists are folded into the client-to-server
+	// ones; servers practically always offer the same lists in both directions.
+	return &types.SSHAlgorithms{
+		KeyExchanges:      lists[0],
+		HostKeyAlgorithms: lists[1],
+		Ciphers:           union(lists[2], lists[3]),
+		MACs:              union(lists[4], lists[5]),
+	}, nil
+}
+
+func union(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, name := range b {
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// expectedAlgorithms picks the first algorithm of each client preference list that
+// the server also offered, as RFC 4253 does. The library may still settle
+// differently, for example when it does not support a configured name.
+func expectedAlgorithms(c *Config, offered *types.SSHAlgorithms) *types.SSHExpectedAlgorithms {
+	pick := func(client, fallback, server []string) string {
+		if len(client) == 0 {
+			client = fallback
+		}
+		for _, name := range client {
+			if slices.Contains(server, name) {
+				return name
+			}
+		}
+		return ""
+	}
+
+	cipher := pick(c.Ciphers, defaultCiphers, offered.Ciphers)
+	mac := pick(c.MACs, defaultMACs, offered.MACs)
+	if isAEADCipher(cipher) {
+		mac = ""
+	}
+	return &types.SSHExpectedAlgorithms{
+		KeyExchange:          pick(c.KeyExchanges, defaultKeyExchanges, offered.KeyExchanges),
+		HostKeyAlgorithm:     pick(c.HostKeyAlgorithms, defaultHostKeyAlgorithms, offered.HostKeyAlgorithms),
+		CipherClientToServer: cipher,
+		CipherServerToClient: cipher,
+		MACClientToServer:    mac,
+		MACServerToClient:    mac,
+	}
+}
+
+func isAEADCipher(name string) bool {
+	return strings.HasSuffix(name, "-gcm@openssh.com") || name == "chacha20-poly1305@openssh.com"
+}
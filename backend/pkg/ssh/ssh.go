@@ -3,18 +3,18 @@ package ssh
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Q191/GTerm/backend/types"
 
 	"net"
-	"os"
-	"path/filepath"
 
 	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
 	"github.com/skeema/knownhosts"
 	"golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
 )
 
 type Config struct {
@@ -25,13 +25,29 @@ type Config struct {
 	Password            string
 	PrivateKey          string
 	Passphrase          string
-	TrustUnknownHost    bool
 	Timeout             time.Duration
 	Ciphers             []string
 	KeyExchanges        []string
 	MACs                []string
 	HostKeyAlgorithms   []string
 	PublicKeyAlgorithms []string
+	// Identities is the ordered fallback chain; when empty the single credential
+	// above is used.
+	Identities           []*Identity
+	UseDefaultIdentities bool
+	MaxAuthTries         int
+	PreferredIdentity    string
+	OnAuthenticated      func(identity *Identity)
+	// Jump is the host the connection is tunnelled through. Proxy is a socks5://,
+	// socks5h:// or http:// URL used when there is no jump host.
+	Jump  *Config
+	Proxy string
+	// KeepAlive is the interval of keepalive requests, zero disables them.
+	// OnKeepAlive receives the round trip time of every answered request.
+	KeepAlive   time.Duration
+	OnKeepAlive func(rtt time.Duration)
+	// Trace, when set, records a verbose log of the connection attempt.
+	Trace *Trace
 }
 
 func NewSSHClient(c *Config, logger initialize.Logger) (*ssh.Client, error) {
@@ -45,58 +61,14 @@ func NewSSHClient(c *Config, logger initialize.Logger) (*ssh.Client, error) {
 	logger.Info("Connecting to SSH server %s:%d", c.Host, c.Port)
 	host := fmt.Sprintf("%s:%d", c.Host, c.Port)
 
-	var auth []ssh.AuthMethod
-	switch c.AuthMethod {
-	case enums.Password:
-		auth = append(auth, ssh.Password(c.Password))
-		auth = append(auth, ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
-			answers := make([]string, len(questions))
-			if len(questions) == 1 {
-				answers[0] = c.Password
-			}
-			return answers, nil
-		}))
-		logger.Info("Using password authentication")
-	case enums.PrivateKey:
-		var signer ssh.Signer
-		var err error
-
-		if c.Passphrase == "" {
-			signer, err = ssh.ParsePrivateKey([]byte(c.PrivateKey))
-		} else {
-			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(c.PrivateKey), []byte(c.Passphrase))
-		}
-
-		if err != nil {
-			logger.Error("Failed to parse private key: %v", err)
-			return nil, err
-		}
-
-		auth = append(auth, ssh.PublicKeys(signer))
-		logger.Info("Using private key authentication")
-	default:
+	identities := c.identities(logger)
+	if len(identities) == 0 {
 		return nil, errors.New("unsupported authentication method")
 	}
 
-	var hostKeyCallback ssh.HostKeyCallback
-	var hostKeyAlgorithms []string
-
-	if c.TrustUnknownHost {
-		hostKeyCallback = ssh.InsecureIgnoreHostKey()
-		logger.Warn("Configured to trust all unknown hosts, this may pose a security risk")
-	} else {
-		knownHostsFile := filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")
-		logger.Debug("Using known_hosts file: %s", knownHostsFile)
-
-		db, err := knownhosts.NewDB(knownHostsFile)
-		if err != nil {
-			logger.Error("Failed to load known_hosts database: %v", err)
-			hostKeyCallback = ssh.InsecureIgnoreHostKey()
-		} else {
-			hostKeyCallback = db.HostKeyCallback()
-			hostKeyAlgorithms = db.HostKeyAlgorithms(host)
-			logger.Info("Using known_hosts for host key verification")
-		}
+	hostKeyCallback, hostKeyAlgorithms, err := hostKeyPolicy(host, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	timeout := 10 * time.Second
@@ -105,28 +77,34 @@ func NewSSHClient(c *Config, logger initialize.Logger) (*ssh.Client, error) {
 	}
 
 	clientConfig := &ssh.ClientConfig{
-		User:            c.User,
-		Auth:            auth,
 		Timeout:         timeout,
 		HostKeyCallback: hostKeyCallback,
 	}
 
-	if len(hostKeyAlgorithms) > 0 {
-		clientConfig.HostKeyAlgorithms = hostKeyAlgorithms
-		logger.Info("Using host key algorithms from known_hosts: %v", hostKeyAlgorithms)
-	} else if len(c.HostKeyAlgorithms) > 0 {
+	switch {
+	case len(c.HostKeyAlgorithms) > 0 && len(hostKeyAlgorithms) > 0:
+		// prefer the algorithms already pinned in known_hosts, within the configured list
+		var algorithms []string
+		for _, algorithm := range hostKeyAlgorithms {
+			if slices.Contains(c.HostKeyAlgorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+		for _, algorithm := range c.HostKeyAlgorithms {
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+		clientConfig.HostKeyAlgorithms = algorithms
+		logger.Info("Using custom host key algorithms: %v", algorithms)
+	case len(c.HostKeyAlgorithms) > 0:
 		clientConfig.HostKeyAlgorithms = c.HostKeyAlgorithms
 		logger.Info("Using custom host key algorithms: %v", c.HostKeyAlgorithms)
-	} else {
-		clientConfig.HostKeyAlgorithms = []string{
-			ssh.KeyAlgoED25519,
-			ssh.KeyAlgoECDSA256,
-			ssh.KeyAlgoECDSA384,
-			ssh.KeyAlgoECDSA521,
-			ssh.KeyAlgoRSASHA512,
-			ssh.KeyAlgoRSA,
-			ssh.KeyAlgoDSA,
-		}
+	case len(hostKeyAlgorithms) > 0:
+		clientConfig.HostKeyAlgorithms = hostKeyAlgorithms
+		logger.Info("Using host key algorithms from known_hosts: %v", hostKeyAlgorithms)
+	default:
+		clientConfig.HostKeyAlgorithms = slices.Clone(defaultHostKeyAlgorithms)
 		logger.Info("Using default host key algorithm list")
 	}
 
@@ -145,31 +123,77 @@ func NewSSHClient(c *Config, logger initialize.Logger) (*ssh.Client, error) {
 		logger.Info("Using custom MAC list: %v", c.MACs)
 	}
 
-	logger.Info("Starting SSH connection to server, %s@%s", c.User, host)
+	if len(c.PublicKeyAlgorithms) > 0 {
+		logger.Info("Using custom public key algorithm list: %v", c.PublicKeyAlgorithms)
+	}
+
+	var lastErr error
+	for _, batch := range groupIdentitiesByUser(identities) {
+		attempt := newAuthAttempt(c.maxAuthTries(), c.PublicKeyAlgorithms, c.Trace)
+		clientConfig.User = batch[0].User
+		clientConfig.Auth = attempt.methods(batch, logger)
+		if len(clientConfig.Auth) == 0 {
+			continue
+		}
 
-	client, err := ssh.Dial("tcp", host, clientConfig)
-	if err != nil {
-		if knownhosts.IsHostUnknown(err) {
-			logger.Info("Unknown host, attempting to get host key: %s", host)
-			key, keyErr := getHostKey(c, logger)
-			if keyErr == nil && key != nil {
-				fingerprint := ssh.FingerprintSHA256(key)
-				logger.Info("Successfully obtained unknown host key, fingerprint: %s", fingerprint)
-				return nil, &types.FingerprintError{
-					Host:        host,
-					Fingerprint: fingerprint,
+		logger.Info("Starting SSH connection to server, %s@%s, identities: %d", clientConfig.User, host, len(batch))
+		client, err := dial(host, clientConfig, c, logger)
+		if err == nil {
+			logger.Info("SSH connection successful, %s@%s", clientConfig.User, host)
+			if c.KeepAlive > 0 {
+				go keepAlive(client, c.KeepAlive, c.OnKeepAlive, logger)
+			}
+			if attempt.current != nil {
+				c.Trace.Logf(TraceStageAuth, "Authenticated as %s using %s", clientConfig.User, attempt.current.Key)
+				if c.OnAuthenticated != nil {
+					c.OnAuthenticated(attempt.current)
 				}
 			}
-		} else if knownhosts.IsHostKeyChanged(err) {
-			logger.Warn("Host key has changed! This may indicate a MitM attack, host: %s", host)
+			return client, nil
 		}
-		logger.Error("SSH connection failed: %v", err)
-		return nil, err
+		if !isAuthError(err) {
+			return nil, handleDialError(c, host, err, logger)
+		}
+		logger.Warn("Authentication failed for %s@%s: %v", clientConfig.User, host, err)
+		c.Trace.Logf(TraceStageAuth, "Authentication failed for %s: %v", clientConfig.User, err)
+		lastErr = err
 	}
 
-	logger.Info("SSH connection successful, %s@%s", c.User, host)
+	if lastErr == nil {
+		lastErr = errors.New("no usable authentication identities")
+	}
+	logger.Error("SSH connection failed: %v", lastErr)
+	return nil, lastErr
+}
 
-	return client, nil
+func handleDialError(c *Config, host string, err error, logger initialize.Logger) error {
+	if knownhosts.IsHostUnknown(err) {
+		logger.Info("Unknown host, attempting to get host key: %s", host)
+		key, keyErr := getHostKey(c, logger)
+		if keyErr == nil && key != nil {
+			fingerprint := ssh.FingerprintSHA256(key)
+			logger.Info("Successfully obtained unknown host key, fingerprint: %s", fingerprint)
+			return &types.FingerprintError{
+				Host:        host,
+				Fingerprint: fingerprint,
+			}
+		}
+	} else if knownhosts.IsHostKeyChanged(err) {
+		logger.Warn("Host key has changed! This may indicate a MitM attack, host: %s", host)
+		changedErr := &types.HostKeyChangedError{Host: host}
+		var keyErr *xknownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			for _, known := range keyErr.Want {
+				changedErr.OldFingerprints = append(changedErr.OldFingerprints, ssh.FingerprintSHA256(known.Key))
+			}
+		}
+		if key, keyErr := getHostKey(c, logger); keyErr == nil && key != nil {
+			changedErr.Fingerprint = ssh.FingerprintSHA256(key)
+			return changedErr
+		}
+	}
+	logger.Error("SSH connection failed: %v", err)
+	return err
 }
 
 func getHostKey(c *Config, logger initialize.Logger) (hostKey ssh.PublicKey, err error) {
@@ -188,8 +212,12 @@ func getHostKey(c *Config, logger initialize.Logger) (hostKey ssh.PublicKey, err
 			return nil
 		},
 	}
+	clientConfig.KeyExchanges = c.KeyExchanges
+	clientConfig.Ciphers = c.Ciphers
+	clientConfig.MACs = c.MACs
+	clientConfig.HostKeyAlgorithms = c.HostKeyAlgorithms
 
-	conn, err := ssh.Dial("tcp", host, clientConfig)
+	conn, err := dialClient(c, host, clientConfig, logger)
 	if err != nil {
 		if hostKey != nil {
 			logger.Info("Successfully obtained host key, fingerprint: %s", ssh.FingerprintSHA256(hostKey))
@@ -212,8 +240,7 @@ func getHostKey(c *Config, logger initialize.Logger) (hostKey ssh.PublicKey, err
 
 func AddFingerprint(conf *Config, host, fingerprint string, logger initialize.Logger) error {
 	logger.Info("Adding host fingerprint, host: %s, fingerprint: %s", host, fingerprint)
-	knownHostsFile := filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")
-	key, err := getHostKey(conf, logger)
+	key, err := getHostKey(conf.hop(host), logger)
 	if err != nil {
 		return err
 	}
@@ -225,19 +252,5 @@ func AddFingerprint(conf *Config, host, fingerprint string, logger initialize.Lo
 		return errors.New("fingerprint mismatch")
 	}
 
-	f, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		logger.Error("Failed to open known_hosts file: %v", err)
-		return err
-	}
-	defer func(f *os.File) {
-		_ = f.Close()
-	}(f)
-
-	if err = knownhosts.WriteKnownHost(f, host, nil, key); err != nil {
-		logger.Error("Failed to write to known_hosts file: %v", err)
-		return err
-	}
-	logger.Info("Successfully added fingerprint to known_hosts, fingerprint: %s", fingerprint)
-	return nil
+	return appendKnownHost(host, key, logger)
 }
@@ -0,0 +1,212 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	TraceStageDNS     = "dns"
+	TraceStageTCP     = "tcp"
+	TraceStageVersion = "version"
+	TraceStageKex     = "kex"
+	TraceStageAuth    = "auth"
+	TraceStageChannel = "channel"
+	TraceStagePty     = "pty"
+	TraceStageResult  = "result"
+
+	maxSniffBytes = 64 * 1024
+)
+
+// Trace collects a verbose, ssh -vvv style record of one connection attempt.
+// All methods are safe to call on a nil *Trace, so tracing stays opt-in.
+type Trace struct {
+	mu    sync.Mutex
+	start time.Time
+	data  *types.SSHTrace
+}
+
+func NewTrace(connID uint, host string) *Trace {
+	now := time.Now()
+	return &Trace{
+		start: now,
+		data: &types.SSHTrace{
+			ID:           uuid.New().String(),
+			ConnectionID: connID,
+			Host:         host,
+			StartedAt:    now,
+		},
+	}
+}
+
+func (t *Trace) Logf(stage, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.data.Events = append(t.data.Events, &types.SSHTraceEvent{
+		Time:    now,
+		Elapsed: now.Sub(t.start).Milliseconds(),
+		Stage:   stage,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (t *Trace) Finish(err error) {
+	if t == nil {
+		return
+	}
+	if err != nil {
+		t.Logf(TraceStageResult, "Connection failed: %v", err)
+	} else {
+		t.Logf(TraceStageResult, "Connection established")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.data.FinishedAt = &now
+	t.data.Success = err == nil
+	if err != nil {
+		t.data.Error = err.Error()
+	}
+}
+
+// Snapshot returns a copy that is safe to hand out while the trace is still written.
+func (t *Trace) Snapshot() *types.SSHTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := *t.data
+	data.Events = append([]*types.SSHTraceEvent(nil), t.data.Events...)
+	return &data
+}
+
+// dial mirrors ssh.Dial, recording resolution, connect timing and the unencrypted
+// part of the handshake when a trace is attached.
+func dial(addr string, config *ssh.ClientConfig, c *Config, logger initialize.Logger) (*ssh.Client, error) {
+	trace := c.Trace
+	proxyURL, _ := url.Parse(c.Proxy)
+	if trace != nil && net.ParseIP(c.Host) == nil && resolvesLocally(c, proxyURL) {
+		start := time.Now()
+		addrs, err := net.DefaultResolver.LookupHost(context.Background(), c.Host)
+		if err != nil {
+			trace.Logf(TraceStageDNS, "Failed to resolve %s: %v", c.Host, err)
+		} else {
+			trace.Logf(TraceStageDNS, "Resolved %s to %s in %s", c.Host, strings.Join(addrs, ", "), time.Since(start))
+		}
+	}
+
+	start := time.Now()
+	switch {
+	case c.Jump != nil:
+		trace.Logf(TraceStageTCP, "Connecting to %s through jump host %s:%d", addr, c.Jump.Host, c.Jump.Port)
+	case c.Proxy != "" && proxyURL != nil:
+		// the URL may carry the proxy credential
+		trace.Logf(TraceStageTCP, "Connecting to %s through %s proxy %s", addr, proxyURL.Scheme, proxyURL.Host)
+	default:
+		trace.Logf(TraceStageTCP, "Connecting to %s", addr)
+	}
+	conn, err := dialTarget(c, addr, config.Timeout, logger)
+	if err != nil {
+		trace.Logf(TraceStageTCP, "Connect failed after %s: %v", time.Since(start), err)
+		return nil, err
+	}
+	trace.Logf(TraceStageTCP, "Connected to %s from %s in %s", conn.RemoteAddr(), conn.LocalAddr(), time.Since(start))
+
+	if trace != nil {
+		conn = &traceConn{Conn: conn, conf: c, trace: trace}
+		config.BannerCallback = func(message string) error {
+			trace.Logf(TraceStageAuth, "Server banner: %s", strings.TrimSpace(message))
+			return nil
+		}
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	trace.Logf(TraceStageAuth, "Handshake completed, client version: %s", sshConn.ClientVersion())
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// resolvesLocally reports whether the client looks the host up itself. Jump hosts
+// and every proxy but socks5:// resolve it on the far side.
+func resolvesLocally(c *Config, proxyURL *url.URL) bool {
+	if c.Jump != nil {
+		return false
+	}
+	return c.Proxy == "" || proxyURL != nil && proxyURL.Scheme == "socks5"
+}
+
+// traceConn sniffs the server version string and KEXINIT from the bytes read
+// before encryption starts.
+type traceConn struct {
+	net.Conn
+	conf  *Config
+	trace *Trace
+	buf   bytes.Buffer
+	done  bool
+}
+
+func (c *traceConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 && !c.done {
+		c.buf.Write(p[:n])
+		c.sniff()
+	}
+	return n, err
+}
+
+func (c *traceConn) sniff() {
+	reader := bufio.NewReader(bytes.NewReader(c.buf.Bytes()))
+	version, err := readServerVersion(reader)
+	if err != nil {
+		c.done = c.buf.Len() > maxSniffBytes
+		return
+	}
+	payload, err := readPacket(reader)
+	if err != nil {
+		c.done = c.buf.Len() > maxSniffBytes
+		return
+	}
+	c.done = true
+
+	c.trace.Logf(TraceStageVersion, "Server version: %s", version)
+	offered, err := parseKexInit(payload)
+	if err != nil {
+		c.trace.Logf(TraceStageKex, "Failed to parse server KEXINIT: %v", err)
+		return
+	}
+	c.trace.Logf(TraceStageKex, "Server key exchanges: %s", strings.Join(offered.KeyExchanges, ","))
+	c.trace.Logf(TraceStageKex, "Server host key algorithms: %s", strings.Join(offered.HostKeyAlgorithms, ","))
+	c.trace.Logf(TraceStageKex, "Server ciphers: %s", strings.Join(offered.Ciphers, ","))
+	c.trace.Logf(TraceStageKex, "Server MACs: %s", strings.Join(offered.MACs, ","))
+
+	expected := expectedAlgorithms(c.conf, offered)
+	c.trace.Logf(TraceStageKex, "Expected negotiated kex: %s, host key: %s, cipher: %s, mac: %s",
+		orNone(expected.KeyExchange),
+		orNone(expected.HostKeyAlgorithm),
+		orNone(expected.CipherClientToServer),
+		orNone(expected.MACClientToServer))
+}
+
+func orNone(name string) string {
+	if name == "" {
+		return "<none>"
+	}
+	return name
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Q191/GTerm/backend/consts"
 	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
 	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
@@ -21,20 +22,27 @@ import (
 type SSH struct {
 	conf      *commonssh.Config
 	ws        *websocket.Conn
+	client    *ssh.Client
 	session   *ssh.Session
 	stdinPipe io.WriteCloser
 	writer    *writer
+	stats     *terminal.Stats
+	done      chan struct{}
 	logger    initialize.Logger
 }
 
 type writer struct {
 	buffer bytes.Buffer
+	stats  *terminal.Stats
 	mu     sync.Mutex
 }
 
 func (w *writer) Write(p []byte) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.stats != nil {
+		w.stats.AddIn(len(p))
+	}
 	return w.buffer.Write(p)
 }
 
@@ -56,18 +64,23 @@ func (w *writer) Reset() {
 	w.buffer.Reset()
 }
 
-func NewSSH(conf *commonssh.Config, ws *websocket.Conn, logger initialize.Logger) *SSH {
+func NewSSH(conf *commonssh.Config, ws *websocket.Conn, stats *terminal.Stats, logger initialize.Logger) *SSH {
 	return &SSH{
 		conf:   conf,
 		ws:     ws,
+		stats:  stats,
+		done:   make(chan struct{}),
 		logger: logger,
-		writer: new(writer),
+		writer: &writer{stats: stats},
 	}
 }
 
 func (s *SSH) Connect() (*SSH, error) {
 	host := fmt.Sprintf("%s:%d", s.conf.Host, s.conf.Port)
 	s.logger.Info("Attempting to connect SSH, host: %s, port: %d", s.conf.Host, s.conf.Port)
+	if s.conf.KeepAlive > 0 {
+		s.conf.OnKeepAlive = s.stats.SetLatency
+	}
 	client, err := commonssh.NewSSHClient(s.conf, s.logger)
 	if err != nil {
 		var fingerprintErr *types.FingerprintError
@@ -81,14 +94,18 @@ func (s *SSH) Connect() (*SSH, error) {
 		return s, err
 	}
 	s.logger.Info("SSH connection successful, %s@%s", s.conf.User, host)
+	s.client = client
 
 	s.logger.Info("Creating SSH session")
+	s.conf.Trace.Logf(commonssh.TraceStageChannel, "Opening session channel")
 	session, err := client.NewSession()
 	if err != nil {
 		s.logger.Error("Failed to create SSH session: %v", err)
+		s.conf.Trace.Logf(commonssh.TraceStageChannel, "Session channel rejected: %v", err)
 		return s, err
 	}
 	s.session = session
+	s.conf.Trace.Logf(commonssh.TraceStageChannel, "Session channel opened")
 
 	s.logger.Debug("Getting session stdin pipe")
 	s.stdinPipe, err = s.session.StdinPipe()
@@ -109,21 +126,60 @@ func (s *SSH) Connect() (*SSH, error) {
 
 	// TODO: 支持自定义终端类型
 	s.logger.Debug("Requesting PTY terminal, type: xterm")
+	s.conf.Trace.Logf(commonssh.TraceStagePty, "Requesting pty, term: xterm")
 	if err = s.session.RequestPty("xterm", 0, 0, modes); err != nil {
 		s.logger.Error("Failed to request PTY terminal: %v", err)
+		s.conf.Trace.Logf(commonssh.TraceStagePty, "Pty request failed: %v", err)
 		return nil, err
 	}
 
 	s.logger.Debug("Starting shell")
+	s.conf.Trace.Logf(commonssh.TraceStageChannel, "Requesting shell")
 	if err = s.session.Shell(); err != nil {
 		s.logger.Error("Failed to start shell: %v", err)
+		s.conf.Trace.Logf(commonssh.TraceStageChannel, "Shell request failed: %v", err)
 		return nil, err
 	}
 
 	s.logger.Info("SSH session ready")
+	if s.conf.KeepAlive == 0 {
+		go s.measureLatency()
+	}
 	return s, nil
 }
 
+// measureLatency periodically times a keepalive request round trip until the
+// session is closed. It only runs when the connection sends no keepalive requests
+// of its own, whose round trips are used otherwise.
+func (s *SSH) measureLatency() {
+	ticker := time.NewTicker(consts.SessionStatsInterval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if _, _, err := s.client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+			s.logger.Debug("Keepalive request failed: %v", err)
+			return
+		}
+		s.stats.SetLatency(time.Since(start))
+
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SSH) sendStats() {
+	s.stats.Sample()
+	if err := s.ws.WriteJSON(&types.Message{
+		Type:    enums.TerminalTypeStats,
+		Content: s.stats.Snapshot(),
+	}); err != nil {
+		s.logger.Error("failed write stats to websocket: %v", err)
+	}
+}
+
 func (s *SSH) flushWriter() {
 	if len(s.writer.String()) != 0 {
 		if err := s.ws.WriteJSON(&types.Message{
@@ -160,9 +216,11 @@ func (s *SSH) Input(quitSignal chan bool) {
 					}
 				}
 			case enums.TerminalTypeCMD:
-				if _, err = s.stdinPipe.Write([]byte(msg.Cmd)); err != nil {
+				n, err := s.stdinPipe.Write([]byte(msg.Cmd))
+				if err != nil {
 					s.logger.Error("failed write command to stdin pipe: %v", err)
 				}
+				s.stats.AddOut(n)
 			}
 		}
 	}
@@ -173,6 +231,8 @@ func (s *SSH) Output(quitSignal chan bool) {
 	defer s.setQuit(quitSignal)
 	tick := time.NewTicker(time.Millisecond * time.Duration(5))
 	defer tick.Stop()
+	statsTick := time.NewTicker(consts.SessionStatsInterval)
+	defer statsTick.Stop()
 	for {
 		select {
 		case <-quitSignal:
@@ -180,15 +240,21 @@ func (s *SSH) Output(quitSignal chan bool) {
 			return
 		case <-tick.C:
 			s.flushWriter()
+		case <-statsTick.C:
+			s.sendStats()
 		}
 	}
 }
 
 func (s *SSH) close() {
 	s.logger.Info("Closing SSH session")
+	close(s.done)
 	if s.session != nil {
 		_ = s.session.Close()
 	}
+	if s.client != nil {
+		_ = s.client.Close()
+	}
 }
 
 func (s *SSH) Wait(quitSignal chan bool) {
@@ -0,0 +1,87 @@
+package terminal
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/google/uuid"
+)
+
+// Stats tracks traffic, latency and uptime of a single terminal session.
+type Stats struct {
+	id           string
+	connectionID uint
+	host         string
+	startedAt    time.Time
+	bytesIn      atomic.Int64
+	bytesOut     atomic.Int64
+	latency      atomic.Int64
+
+	mu         sync.Mutex
+	sampledAt  time.Time
+	sampledIn  int64
+	sampledOut int64
+	rateIn     float64
+	rateOut    float64
+}
+
+func NewStats(connectionID uint, host string) *Stats {
+	now := time.Now()
+	return &Stats{
+		id:           uuid.New().String(),
+		connectionID: connectionID,
+		host:         host,
+		startedAt:    now,
+		sampledAt:    now,
+	}
+}
+
+func (s *Stats) ID() string {
+	return s.id
+}
+
+func (s *Stats) AddIn(n int) {
+	s.bytesIn.Add(int64(n))
+}
+
+func (s *Stats) AddOut(n int) {
+	s.bytesOut.Add(int64(n))
+}
+
+func (s *Stats) SetLatency(d time.Duration) {
+	s.latency.Store(int64(d))
+}
+
+// Sample recomputes the throughput over the time since the previous sample.
+func (s *Stats) Sample() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(s.sampledAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	in, out := s.bytesIn.Load(), s.bytesOut.Load()
+	s.rateIn = float64(in-s.sampledIn) / elapsed
+	s.rateOut = float64(out-s.sampledOut) / elapsed
+	s.sampledAt, s.sampledIn, s.sampledOut = now, in, out
+}
+
+func (s *Stats) Snapshot() *types.SessionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &types.SessionStats{
+		SessionID:     s.id,
+		ConnectionID:  s.connectionID,
+		Host:          s.host,
+		StartedAt:     s.startedAt,
+		Uptime:        int64(time.Since(s.startedAt).Seconds()),
+		Latency:       time.Duration(s.latency.Load()).Milliseconds(),
+		BytesIn:       s.bytesIn.Load(),
+		BytesOut:      s.bytesOut.Load(),
+		ThroughputIn:  s.rateIn,
+		ThroughputOut: s.rateOut,
+	}
+}
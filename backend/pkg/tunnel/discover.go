@@ -0,0 +1,106 @@
+package tunnel
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/pkg/exec"
+	"github.com/Q191/GTerm/backend/types"
+)
+
+// discoverCommand prefers ss and falls back to netstat on hosts without iproute2.
+const discoverCommand = "ss -ltnp 2>/dev/null || netstat -ltnp 2>/dev/null || netstat -ltn 2>/dev/null"
+
+var (
+	ssProcessPattern      = regexp.MustCompile(`\("([^"]+)",pid=(\d+)`)
+	netstatProcessPattern = regexp.MustCompile(`^(\d+)/(.+)$`)
+)
+
+// DiscoverListeningPorts lists the TCP sockets the host listens on. Process names
+// are only reported for sockets the login user is allowed to inspect.
+func DiscoverListeningPorts(adapter *exec.Adapter) ([]*types.ListeningPort, error) {
+	result := adapter.Run(discoverCommand)
+	if result.StdOut() == "" {
+		if err := result.Error(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("neither ss nor netstat is available on the host")
+	}
+	return ParseListeningPorts(result.StdOut()), nil
+}
+
+// ParseListeningPorts understands the output of `ss -ltnp` and `netstat -ltnp`.
+func ParseListeningPorts(output string) []*types.ListeningPort {
+	ports := make([]*types.ListeningPort, 0)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		var port *types.ListeningPort
+		switch {
+		case fields[0] == "LISTEN":
+			// State Recv-Q Send-Q Local:Port Peer:Port [Process]
+			port = parseListenAddress(fields[3])
+			if port != nil && len(fields) > 5 {
+				if m := ssProcessPattern.FindStringSubmatch(strings.Join(fields[5:], " ")); m != nil {
+					port.Process = m[1]
+					port.PID, _ = strconv.Atoi(m[2])
+				}
+			}
+		case strings.HasPrefix(fields[0], "tcp") && len(fields) >= 6 && fields[5] == "LISTEN":
+			// Proto Recv-Q Send-Q Local Foreign State [PID/Program]
+			port = parseListenAddress(fields[3])
+			if port != nil && len(fields) > 6 {
+				if m := netstatProcessPattern.FindStringSubmatch(fields[6]); m != nil {
+					port.PID, _ = strconv.Atoi(m[1])
+					port.Process = strings.TrimSuffix(m[2], ":")
+				}
+			}
+		}
+		if port != nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func parseListenAddress(address string) *types.ListeningPort {
+	idx := strings.LastIndex(address, ":")
+	if idx < 0 {
+		return nil
+	}
+	port, err := strconv.ParseUint(address[idx+1:], 10, 16)
+	if err != nil {
+		return nil
+	}
+	host := strings.Trim(address[:idx], "[]")
+	// drop the interface suffix of scoped addresses such as 127.0.0.53%lo
+	if i := strings.Index(host, "%"); i >= 0 {
+		host = host[:i]
+	}
+	if host == "" || host == "*" {
+		host = "0.0.0.0"
+	}
+	return &types.ListeningPort{
+		Bind: host,
+		Port: uint16(port),
+	}
+}
+
+// ForwardTarget returns the address a local forward should dial on the server to
+// reach the listening socket.
+func ForwardTarget(port *types.ListeningPort) string {
+	host := port.Bind
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		if ip.To4() != nil {
+			host = "127.0.0.1"
+		} else {
+			host = "::1"
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port.Port)))
+}
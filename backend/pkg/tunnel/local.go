@@ -0,0 +1,24 @@
+package tunnel
+
+import (
+	"net"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"golang.org/x/crypto/ssh"
+)
+
+// NewLocal starts a local forward (ssh -L): connections accepted on bind are opened
+// to target from the SSH server.
+func NewLocal(client *ssh.Client, connectionID uint, bind, target string, logger initialize.Logger) (*Tunnel, error) {
+	listener, err := net.Listen("tcp", bind)
+	if err != nil {
+		return nil, err
+	}
+	t := newTunnel(client, connectionID, enums.TunnelTypeLocal, listener.Addr().String(), target, logger)
+	t.serve(listener, func(conn net.Conn) (net.Conn, error) {
+		return client.Dial("tcp", target)
+	})
+	logger.Info("Local forward %s started: %s -> %s", t.id, t.bind, target)
+	return t, nil
+}
@@ -0,0 +1,41 @@
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"golang.org/x/crypto/ssh"
+)
+
+// NewRemote starts a remote forward (ssh -R): the server listens on bind and every
+// connection it accepts is opened to target from this machine. Closing the tunnel
+// cancels the forward on the server.
+func NewRemote(client *ssh.Client, connectionID uint, bind, target string, logger initialize.Logger) (*Tunnel, error) {
+	listener, err := client.Listen("tcp", bind)
+	if err != nil {
+		return nil, remoteBindError(bind, err)
+	}
+	t := newTunnel(client, connectionID, enums.TunnelTypeRemote, bind, target, logger)
+	t.serve(listener, func(conn net.Conn) (net.Conn, error) {
+		return net.Dial("tcp", target)
+	})
+	logger.Info("Remote forward %s started: %s -> %s", t.id, bind, target)
+	return t, nil
+}
+
+// remoteBindError explains the usual reasons a server refuses tcpip-forward, since
+// the protocol only reports that the request was denied.
+func remoteBindError(bind string, err error) error {
+	if !strings.Contains(err.Error(), "request denied") {
+		return fmt.Errorf("failed to bind %s on server: %w", bind, err)
+	}
+	host, _, _ := net.SplitHostPort(bind)
+	reason := "the port may already be in use or forwarding is disabled (AllowTcpForwarding)"
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		reason = "the port may already be in use, or binding non-loopback addresses requires GatewayPorts"
+	}
+	return fmt.Errorf("server refused to bind %s: %s", bind, reason)
+}
@@ -0,0 +1,210 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	socksVersion          = 0x05
+	socksAuthNone         = 0x00
+	socksAuthPassword     = 0x02
+	socksAuthUnacceptable = 0xff
+	socksCmdConnect       = 0x01
+	socksAddrIPv4         = 0x01
+	socksAddrDomain       = 0x03
+	socksAddrIPv6         = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyHostUnreachable     = 0x04
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddrNotSupported    = 0x08
+
+	socksHandshakeTimeout = 10 * time.Second
+)
+
+var errSocksAuthFailed = errors.New("socks5 authentication failed")
+
+// NewDynamic starts a dynamic forward (ssh -D): a SOCKS5 server on bind whose CONNECT
+// requests are opened from the SSH server. Only CONNECT is supported.
+func NewDynamic(client *ssh.Client, connectionID uint, bind string, opts *types.DynamicForwardOptions, logger initialize.Logger) (*Tunnel, error) {
+	if opts == nil {
+		opts = &types.DynamicForwardOptions{}
+	}
+	listener, err := net.Listen("tcp", bind)
+	if err != nil {
+		return nil, err
+	}
+	target := "socks5"
+	if opts.RemoteDNS {
+		target = "socks5 (remote DNS)"
+	}
+	t := newTunnel(client, connectionID, enums.TunnelTypeDynamic, listener.Addr().String(), target, logger)
+	t.serve(listener, func(conn net.Conn) (net.Conn, error) {
+		return socksHandshake(conn, client, opts)
+	})
+	logger.Info("Dynamic forward %s started on %s", t.id, t.bind)
+	return t, nil
+}
+
+func socksHandshake(conn net.Conn, client *ssh.Client, opts *types.DynamicForwardOptions) (net.Conn, error) {
+	_ = conn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	if err := socksAuthenticate(conn, opts); err != nil {
+		return nil, err
+	}
+
+	// VER CMD RSV ATYP
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+	host, err := socksReadAddr(conn, header[3])
+	if err != nil {
+		socksReply(conn, socksReplyAddrNotSupported)
+		return nil, err
+	}
+	var port [2]byte
+	if _, err = io.ReadFull(conn, port[:]); err != nil {
+		return nil, err
+	}
+	if header[1] != socksCmdConnect {
+		socksReply(conn, socksReplyCommandNotSupported)
+		return nil, fmt.Errorf("unsupported socks command: %d", header[1])
+	}
+
+	if header[3] == socksAddrDomain && !opts.RemoteDNS {
+		addrs, err := net.DefaultResolver.LookupHost(context.Background(), host)
+		if err != nil || len(addrs) == 0 {
+			socksReply(conn, socksReplyHostUnreachable)
+			return nil, fmt.Errorf("failed to resolve %s: %v", host, err)
+		}
+		host = addrs[0]
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:]))))
+	upstream, err := client.Dial("tcp", address)
+	if err != nil {
+		socksReply(conn, socksReplyHostUnreachable)
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	if err = socksReply(conn, socksReplySucceeded); err != nil {
+		_ = upstream.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return upstream, nil
+}
+
+func socksAuthenticate(conn net.Conn, opts *types.DynamicForwardOptions) error {
+	// VER NMETHODS METHODS...
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return err
+	}
+	if header[0] != socksVersion {
+		return fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	required := byte(socksAuthNone)
+	if opts.Username != "" {
+		required = socksAuthPassword
+	}
+	offered := false
+	for _, method := range methods {
+		if method == required {
+			offered = true
+			break
+		}
+	}
+	if !offered {
+		_, _ = conn.Write([]byte{socksVersion, socksAuthUnacceptable})
+		return errors.New("socks client offered no acceptable authentication method")
+	}
+	if _, err := conn.Write([]byte{socksVersion, required}); err != nil {
+		return err
+	}
+	if required == socksAuthNone {
+		return nil
+	}
+
+	// RFC 1929: VER ULEN UNAME PLEN PASSWD
+	var version [2]byte
+	if _, err := io.ReadFull(conn, version[:]); err != nil {
+		return err
+	}
+	username := make([]byte, version[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return err
+	}
+	var plen [1]byte
+	if _, err := io.ReadFull(conn, plen[:]); err != nil {
+		return err
+	}
+	password := make([]byte, plen[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return err
+	}
+	userOK := subtle.ConstantTimeCompare(username, []byte(opts.Username)) == 1
+	passOK := subtle.ConstantTimeCompare(password, []byte(opts.Password)) == 1
+	if !userOK || !passOK {
+		_, _ = conn.Write([]byte{0x01, 0x01})
+		return errSocksAuthFailed
+	}
+	_, err := conn.Write([]byte{0x01, 0x00})
+	return err
+}
+
+func socksReadAddr(conn net.Conn, addrType byte) (string, error) {
+	switch addrType {
+	case socksAddrIPv4:
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		return net.IP(ip).String(), nil
+	case socksAddrIPv6:
+		ip := make([]byte, net.IPv6len)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		return net.IP(ip).String(), nil
+	case socksAddrDomain:
+		var length [1]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		return string(domain), nil
+	default:
+		return "", fmt.Errorf("unsupported socks address type: %d", addrType)
+	}
+}
+
+// socksReply answers a request with an unspecified IPv4 bind address, which clients
+// ignore for CONNECT.
+func socksReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,256 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+// Tunnel accepts connections on a listener and relays each of them through an SSH
+// client. The tunnel owns the client and closes it when stopped.
+type Tunnel struct {
+	id           string
+	profileID    uint
+	connectionID uint
+	tunnelType   enums.TunnelType
+	bind         string
+	target       string
+	startedAt    time.Time
+	logger       initialize.Logger
+
+	client   *ssh.Client
+	listener net.Listener
+	handle   func(conn net.Conn) (net.Conn, error)
+
+	active   atomic.Int64
+	total    atomic.Int64
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+
+	mu     sync.Mutex
+	status enums.TunnelStatus
+	err    error
+	conns  map[net.Conn]struct{}
+	done   chan struct{}
+}
+
+func newTunnel(client *ssh.Client, connectionID uint, tunnelType enums.TunnelType, bind, target string, logger initialize.Logger) *Tunnel {
+	return &Tunnel{
+		id:           uuid.New().String(),
+		connectionID: connectionID,
+		tunnelType:   tunnelType,
+		bind:         bind,
+		target:       target,
+		startedAt:    time.Now(),
+		logger:       logger,
+		client:       client,
+		status:       enums.TunnelStatusRunning,
+		conns:        make(map[net.Conn]struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+func (t *Tunnel) ID() string {
+	return t.id
+}
+
+// SetProfileID links the tunnel to the saved profile it was started from.
+func (t *Tunnel) SetProfileID(id uint) {
+	t.profileID = id
+}
+
+func (t *Tunnel) ConnectionID() uint {
+	return t.connectionID
+}
+
+// Done is closed once the tunnel has stopped, either on request or after a failure.
+func (t *Tunnel) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err returns the failure that stopped the tunnel, or nil if it was stopped on request.
+func (t *Tunnel) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *Tunnel) Snapshot() *types.TunnelStatus {
+	t.mu.Lock()
+	status, err := t.status, t.err
+	t.mu.Unlock()
+
+	snapshot := &types.TunnelStatus{
+		ID:                t.id,
+		ProfileID:         t.profileID,
+		ConnectionID:      t.connectionID,
+		Type:              t.tunnelType,
+		Bind:              t.bind,
+		Target:            t.target,
+		Status:            status,
+		StartedAt:         t.startedAt,
+		ActiveConnections: t.active.Load(),
+		TotalConnections:  t.total.Load(),
+		BytesIn:           t.bytesIn.Load(),
+		BytesOut:          t.bytesOut.Load(),
+	}
+	if err != nil {
+		snapshot.Error = err.Error()
+	}
+	return snapshot
+}
+
+// Stop closes the listener, every relayed connection and the SSH client.
+func (t *Tunnel) Stop() {
+	t.shutdown(nil)
+}
+
+func (t *Tunnel) shutdown(err error) {
+	t.mu.Lock()
+	if t.status != enums.TunnelStatusRunning {
+		t.mu.Unlock()
+		return
+	}
+	if err != nil {
+		t.status, t.err = enums.TunnelStatusFailed, err
+	} else {
+		t.status = enums.TunnelStatusStopped
+	}
+	conns := t.conns
+	t.conns = make(map[net.Conn]struct{})
+	t.mu.Unlock()
+
+	if t.listener != nil {
+		_ = t.listener.Close()
+	}
+	for conn := range conns {
+		_ = conn.Close()
+	}
+	_ = t.client.Close()
+	close(t.done)
+
+	if err != nil {
+		t.logger.Error("Tunnel %s (%s %s -> %s) failed: %v", t.id, t.tunnelType, t.bind, t.target, err)
+	} else {
+		t.logger.Info("Tunnel %s (%s %s -> %s) stopped", t.id, t.tunnelType, t.bind, t.target)
+	}
+}
+
+// serve starts accepting on listener and watches the SSH client, so a dropped
+// connection to the server fails the tunnel.
+func (t *Tunnel) serve(listener net.Listener, handle func(conn net.Conn) (net.Conn, error)) {
+	t.listener = listener
+	t.handle = handle
+	go func() {
+		err := t.client.Wait()
+		if err == nil {
+			err = errors.New("ssh connection closed")
+		}
+		t.shutdown(err)
+	}()
+	go t.acceptLoop()
+}
+
+func (t *Tunnel) acceptLoop() {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			select {
+			case <-t.done:
+			default:
+				t.shutdown(err)
+			}
+			return
+		}
+		go t.relay(conn)
+	}
+}
+
+func (t *Tunnel) track(conn net.Conn) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status != enums.TunnelStatusRunning {
+		return false
+	}
+	t.conns[conn] = struct{}{}
+	return true
+}
+
+func (t *Tunnel) untrack(conn net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.conns, conn)
+}
+
+func (t *Tunnel) relay(conn net.Conn) {
+	t.total.Add(1)
+	if !t.track(conn) {
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		t.untrack(conn)
+		_ = conn.Close()
+	}()
+
+	upstream, err := t.handle(conn)
+	if err != nil {
+		t.logger.Warn("Tunnel %s failed to open %s for %s: %v", t.id, t.target, conn.RemoteAddr(), err)
+		return
+	}
+	if !t.track(upstream) {
+		_ = upstream.Close()
+		return
+	}
+	defer func() {
+		t.untrack(upstream)
+		_ = upstream.Close()
+	}()
+
+	t.active.Add(1)
+	defer t.active.Add(-1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		t.copy(upstream, conn, &t.bytesOut)
+	}()
+	go func() {
+		defer wg.Done()
+		t.copy(conn, upstream, &t.bytesIn)
+	}()
+	wg.Wait()
+}
+
+func (t *Tunnel) copy(dst, src net.Conn, counter *atomic.Int64) {
+	_, _ = io.Copy(&countingWriter{w: dst, n: counter}, src)
+	// let the other direction finish once this side is done
+	if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+	} else {
+		_ = dst.Close()
+	}
+}
+
+// countingWriter adds every write to n as it happens, so the stats of a long-lived
+// connection move while it is still open.
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const defaultAuditLogLimit = 100
+
+var AuditLogSrvSet = wire.NewSet(wire.Struct(new(AuditLogSrv), "*"))
+
+// AuditLogSrv keeps the append-only record of who connected where and what was done
+// with credentials, host keys, files and tunnels.
+type AuditLogSrv struct {
+	Logger     initialize.Logger
+	Query      *query.Query
+	AppContext *initialize.AppContext
+}
+
+type auditLogPage struct {
+	Total int64             `json:"total"`
+	Items []*model.AuditLog `json:"items"`
+}
+
+func (s *AuditLogSrv) ListAuditLogs(filter *types.AuditLogFilter) *resp.Resp {
+	if filter == nil {
+		filter = &types.AuditLogFilter{}
+	}
+	do := s.filter(filter)
+	total, err := do.Count()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLogLimit
+	}
+	items, err := do.Order(s.Query.AuditLog.ID.Desc()).Offset(filter.Offset).Limit(limit).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(&auditLogPage{Total: total, Items: items})
+}
+
+// ExportAuditLogs writes every record matching the filter, ignoring its paging.
+func (s *AuditLogSrv) ExportAuditLogs(filter *types.AuditLogFilter, format enums.ExportFormat, title string) *resp.Resp {
+	if filter == nil {
+		filter = &types.AuditLogFilter{}
+	}
+	logs, err := s.filter(filter).Order(s.Query.AuditLog.ID).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+
+	var data []byte
+	switch format {
+	case enums.ExportFormatCSV:
+		data, err = formatAuditLogsCSV(logs)
+	case enums.ExportFormatJSON:
+		data, err = json.MarshalIndent(logs, "", "  ")
+	default:
+		return resp.FailWithMsg(fmt.Sprintf("unsupported export format: %s", format))
+	}
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+
+	path, err := runtime.SaveFileDialog(s.AppContext.Context(), runtime.SaveDialogOptions{
+		Title:           title,
+		DefaultFilename: fmt.Sprintf("audit-log-%s.%s", time.Now().Format("20060102-150405"), strings.ToLower(string(format))),
+	})
+	if err != nil {
+		s.Logger.Error("Failed to open save dialog: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	if path == "" {
+		return resp.OkWithCode(messages.AuditLogUserCanceled)
+	}
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		s.Logger.Error("Failed to write audit log export: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Exported %d audit log records to %s", len(logs), path)
+	return resp.OkWithCode(messages.AuditLogExportSuccess)
+}
+
+// record appends an entry. Failing to write the audit log is logged but never fails
+// the action itself.
+func (s *AuditLogSrv) record(entry *model.AuditLog) {
+	if err := s.Query.AuditLog.Create(entry); err != nil {
+		s.Logger.Error("Failed to write audit log, action: %s, connID: %d: %v", entry.Action, entry.ConnectionID, err)
+	}
+}
+
+// recordConnectionCredential records an action on the credential of conn. Private
+// credentials have random labels, so the connection is the target.
+func (s *AuditLogSrv) recordConnectionCredential(action enums.AuditAction, conn *model.Connection, credentialID uint) {
+	s.record(&model.AuditLog{Action: action, ConnectionID: conn.ID, CredentialID: credentialID, Target: conn.Label})
+}
+
+// recordCreatedCredential records the private credential created with conn, if any.
+func (s *AuditLogSrv) recordCreatedCredential(conn *model.Connection) {
+	if conn.Credential != nil && !conn.Credential.IsCommonCredential {
+		s.recordConnectionCredential(enums.AuditActionCredentialCreate, conn, conn.Credential.ID)
+	}
+}
+
+// recordTemplateCredential records an action on the private credential of a
+// connection template.
+func (s *AuditLogSrv) recordTemplateCredential(action enums.AuditAction, tmpl *model.ConnectionTemplate, credentialID uint) {
+	s.record(&model.AuditLog{Action: action, CredentialID: credentialID, Target: tmpl.Name, Detail: "connection template"})
+}
+
+func (s *AuditLogSrv) filter(filter *types.AuditLogFilter) query.IAuditLogDo {
+	t := s.Query.AuditLog
+	do := t.Where()
+	if len(filter.Actions) > 0 {
+		actions := make([]string, len(filter.Actions))
+		for i, action := range filter.Actions {
+			actions[i] = string(action)
+		}
+		do = do.Where(t.Action.In(actions...))
+	}
+	if filter.ConnectionID > 0 {
+		do = do.Where(t.ConnectionID.Eq(filter.ConnectionID))
+	}
+	if filter.CredentialID > 0 {
+		do = do.Where(t.CredentialID.Eq(filter.CredentialID))
+	}
+	if filter.From != nil {
+		do = do.Where(t.CreatedAt.Gte(*filter.From))
+	}
+	if filter.To != nil {
+		do = do.Where(t.CreatedAt.Lte(*filter.To))
+	}
+	if filter.Keyword != "" {
+		keyword := "%" + filter.Keyword + "%"
+		do = do.Where(t.Where(t.Target.Like(keyword)).Or(t.Detail.Like(keyword)))
+	}
+	return do
+}
+
+func formatAuditLogsCSV(logs []*model.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"id", "time", "action", "connection_id", "credential_id", "target", "detail", "size"}); err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		if err := w.Write([]string{
+			strconv.FormatUint(uint64(log.ID), 10),
+			log.CreatedAt.Format(time.RFC3339),
+			string(log.Action),
+			strconv.FormatUint(uint64(log.ConnectionID), 10),
+			strconv.FormatUint(uint64(log.CredentialID), 10),
+			log.Target,
+			log.Detail,
+			strconv.FormatInt(log.Size, 10),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -1,10 +1,23 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/Q191/GTerm/backend/consts/messages"
 	"github.com/Q191/GTerm/backend/dal/model"
 	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/search"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/types"
 	"github.com/Q191/GTerm/backend/utils/resp"
 	"github.com/google/uuid"
 	"github.com/google/wire"
@@ -13,36 +26,128 @@ import (
 var ConnectionSrvSet = wire.NewSet(wire.Struct(new(ConnectionSrv), "*"))
 
 type ConnectionSrv struct {
-	Logger initialize.Logger
-	Query  *query.Query
+	Logger        initialize.Logger
+	Query         *query.Query
+	SecretSrv     *SecretSrv
+	AuditLogSrv   *AuditLogSrv
+	TunnelRunners *TunnelRunners
 }
 
 func (s *ConnectionSrv) CreateConnection(conn *model.Connection) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
 	if err := s.Query.Transaction(func(tx *query.Query) error {
-		if conn.CredentialID == nil && conn.Credential != nil {
-			conn.Credential.IsCommonCredential = false
-			conn.Credential.Label = uuid.New().String()
-			if err := tx.Credential.Create(conn.Credential); err != nil {
-				return err
-			}
-			conn.CredentialID = &conn.Credential.ID
+		return createConnection(tx, conn)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	s.AuditLogSrv.recordCreatedCredential(conn)
+	return resp.OkWithCode(messages.CreateSuccess)
+}
+
+// CloneConnection copies the connection under a new unique label. A private
+// credential is copied too, so the two connections can be changed independently.
+func (s *ConnectionSrv) CloneConnection(id uint) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	var clone model.Connection
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		t := tx.Connection
+		src, err := t.Where(t.ID.Eq(id)).Preload(t.Credential).First()
+		if err != nil {
+			return err
 		}
-		if err := tx.Connection.Create(conn); err != nil {
+		clone = *src
+		clone.Common = model.Common{}
+		clone.Metadata, clone.SecurityAudit, clone.Credential = nil, nil, nil
+		clone.FallbackCredentialIDs = slices.Clone(src.FallbackCredentialIDs)
+		clone.Tags = slices.Clone(src.Tags)
+		clone.Attributes = maps.Clone(src.Attributes)
+		clone.Overrides = slices.Clone(src.Overrides)
+		if clone.Label, err = uniqueLabel(tx, src.Label, "copy"); err != nil {
 			return err
 		}
-		return nil
+		if !src.UseCommonCredential && src.Credential != nil {
+			clone.CredentialID = nil
+			if clone.Credential, err = copyCredential(src.Credential); err != nil {
+				return err
+			}
+		}
+		return createConnection(tx, &clone)
 	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
-	return resp.OkWithCode(messages.CreateSuccess)
+	s.AuditLogSrv.recordCreatedCredential(&clone)
+	s.Logger.Info("Cloned connection %d to %d (%s)", id, clone.ID, clone.Label)
+	return resp.OkWithCodeAndData(messages.CreateSuccess, &clone)
+}
+
+// createConnection validates and stores a new connection at the end of its group,
+// creating its private credential when one is given without an ID.
+func createConnection(tx *query.Query, conn *model.Connection) error {
+	if err := validateSettings(&conn.ConnectionSettings); err != nil {
+		return err
+	}
+	var err error
+	if conn.Tags, conn.Attributes, err = normalizeTags(conn.Tags, conn.Attributes); err != nil {
+		return err
+	}
+	if err = checkStaticGroup(tx, conn.GroupID); err != nil {
+		return err
+	}
+	if conn.SortOrder, err = nextConnectionOrder(tx, conn.GroupID); err != nil {
+		return err
+	}
+	applyOverrides(conn)
+	if conn.CredentialID == nil && conn.Credential != nil {
+		if err = createPrivateCredential(tx, conn.Credential); err != nil {
+			return err
+		}
+		conn.CredentialID = &conn.Credential.ID
+	}
+	return tx.Connection.Create(conn)
+}
+
+// createPrivateCredential stores a credential owned by a single connection or
+// template. Its label is random as private credentials are never listed.
+func createPrivateCredential(tx *query.Query, cred *model.Credential) error {
+	cred.IsCommonCredential = false
+	cred.Label = uuid.New().String()
+	return tx.Credential.Create(cred)
 }
 
 func (s *ConnectionSrv) UpdateConnection(conn *model.Connection) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	if err := validateSettings(&conn.ConnectionSettings); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	var err error
+	if conn.Tags, conn.Attributes, err = normalizeTags(conn.Tags, conn.Attributes); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	var credAction enums.AuditAction
+	var credID uint
 	if err := s.Query.Transaction(func(tx *query.Query) error {
 		oldConn, err := tx.Connection.Where(tx.Connection.ID.Eq(conn.ID)).First()
 		if err != nil {
 			return err
 		}
+		// the position only changes through GroupSrv.MoveConnection, or when the
+		// connection is put into another group
+		conn.SortOrder = oldConn.SortOrder
+		if !sameID(oldConn.GroupID, conn.GroupID) {
+			if err = checkStaticGroup(tx, conn.GroupID); err != nil {
+				return err
+			}
+			if conn.SortOrder, err = nextConnectionOrder(tx, conn.GroupID); err != nil {
+				return err
+			}
+		}
+		applyOverrides(conn)
 		if oldConn.UseCommonCredential && !conn.UseCommonCredential {
 			if conn.Credential != nil {
 				conn.Credential.IsCommonCredential = false
@@ -51,6 +156,7 @@ func (s *ConnectionSrv) UpdateConnection(conn *model.Connection) *resp.Resp {
 					return err
 				}
 				conn.CredentialID = &conn.Credential.ID
+				credAction, credID = enums.AuditActionCredentialCreate, conn.Credential.ID
 			}
 		} else if !oldConn.UseCommonCredential && !conn.UseCommonCredential {
 			if oldConn.CredentialID != nil && conn.Credential != nil {
@@ -59,14 +165,16 @@ func (s *ConnectionSrv) UpdateConnection(conn *model.Connection) *resp.Resp {
 				if err = tx.Credential.Where(tx.Credential.ID.Eq(*oldConn.CredentialID)).Save(conn.Credential); err != nil {
 					return err
 				}
+				credAction, credID = enums.AuditActionCredentialUpdate, conn.Credential.ID
 			}
 		}
 
 		if !oldConn.UseCommonCredential && conn.UseCommonCredential {
 			if oldConn.CredentialID != nil {
-				if _, err = tx.Credential.Where(tx.Credential.ID.Eq(*oldConn.CredentialID)).Unscoped().Delete(); err != nil {
+				if err = trashPrivateCredential(tx, *oldConn.CredentialID, oldConn.Label); err != nil {
 					return err
 				}
+				credAction, credID = enums.AuditActionCredentialDelete, *oldConn.CredentialID
 			}
 		}
 
@@ -74,23 +182,83 @@ func (s *ConnectionSrv) UpdateConnection(conn *model.Connection) *resp.Resp {
 	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	if credAction != "" {
+		s.AuditLogSrv.recordConnectionCredential(credAction, conn, credID)
+	}
 	return resp.OkWithCode(messages.UpdateSuccess)
 }
 
 func (s *ConnectionSrv) FindConnectionByID(id uint) *resp.Resp {
-	conn, err := s.FindByID(id)
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	conn, err := s.findConnection(id)
 	if err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	if conn.Credential != nil {
+		// the decrypted secrets leave the backend here, unlike connecting with them
+		s.AuditLogSrv.recordConnectionCredential(enums.AuditActionCredentialDecrypt, conn, conn.Credential.ID)
+	}
 	return resp.OkWithData(conn)
 }
 
+// FindByID returns the effective configuration of the connection for connecting to
+// it, with a credential inherited from its groups loaded and decrypted too.
 func (s *ConnectionSrv) FindByID(id uint) (*model.Connection, error) {
+	conn, err := s.findConnection(id)
+	if err != nil {
+		return nil, err
+	}
+	if conn.Credential == nil && conn.CredentialID != nil {
+		if conn.Credential, err = s.Query.Credential.Where(s.Query.Credential.ID.Eq(*conn.CredentialID)).First(); err != nil {
+			return nil, err
+		}
+		if err = conn.Credential.Decrypt(); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// transportConfig builds the configuration that reaches the SSH server of the
+// connection without logging in: address, algorithms, proxy and jump hosts. Reading
+// host keys only needs this, so the credentials of the connection are neither
+// decrypted nor resolved through external commands and the vault may stay locked.
+// Jump hosts are still logged into with their own credentials.
+func (s *ConnectionSrv) transportConfig(id uint) (*model.Connection, *commonssh.Config, error) {
+	t := s.Query.Connection
+	conn, err := t.Where(t.ID.Eq(id)).First()
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err := groupChain(s.Query, conn.GroupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolveSettings(conn, chain)
+	conf, err := s.hopTransport(conn, []uint{conn.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, conf, nil
+}
+
+// findConnection returns the effective configuration of the connection, with the
+// settings it does not override inherited from its groups and their origins in
+// SettingOrigins. Only its own credential is loaded, an inherited common credential
+// is left at its ID so its secrets never reach the frontend.
+func (s *ConnectionSrv) findConnection(id uint) (*model.Connection, error) {
 	t := s.Query.Connection
 	conn, err := t.Where(t.ID.Eq(id)).Preload(t.Credential, t.Metadata).First()
 	if err != nil {
 		return nil, err
 	}
+	chain, err := groupChain(s.Query, conn.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	resolveSettings(conn, chain)
 	if conn.Credential != nil {
 		if err = conn.Credential.Decrypt(); err != nil {
 			return nil, err
@@ -99,32 +267,286 @@ func (s *ConnectionSrv) FindByID(id uint) (*model.Connection, error) {
 	return conn, nil
 }
 
+// DeleteConnection moves the connection to the trash together with its tunnel
+// profiles and stops the ones that are running.
 func (s *ConnectionSrv) DeleteConnection(id uint) *resp.Resp {
+	var conn *model.Connection
+	item := &model.TrashItem{Kind: enums.TrashKindConnection}
 	if err := s.Query.Transaction(func(tx *query.Query) error {
-		conn, err := tx.Connection.Where(tx.Connection.ID.Eq(id)).First()
-		if err != nil {
+		var err error
+		if conn, err = tx.Connection.Where(tx.Connection.ID.Eq(id)).First(); err != nil {
 			return err
 		}
-		if !conn.UseCommonCredential && conn.CredentialID != nil {
-			if _, err = tx.Credential.Where(tx.Credential.ID.Eq(*conn.CredentialID)).Unscoped().Delete(); err != nil {
-				return err
-			}
-		}
-		if _, err = tx.Connection.Select(tx.Connection.Metadata.Field()).Unscoped().Delete(conn); err != nil {
+		item.Name = conn.Label
+		if err = trashConnection(tx, conn, item); err != nil {
 			return err
 		}
-		return nil
+		return tx.TrashItem.Create(item)
 	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	s.TunnelRunners.stop(item.TunnelIDs...)
+	for _, credID := range item.CredentialIDs {
+		s.AuditLogSrv.recordConnectionCredential(enums.AuditActionCredentialDelete, conn, credID)
+	}
 	return resp.OkWithCode(messages.DeleteSuccess)
 }
 
 func (s *ConnectionSrv) ListConnection() *resp.Resp {
 	t := s.Query.Connection
-	connList, err := t.Preload(t.Metadata, t.Credential).Find()
+	connList, err := t.Preload(t.Metadata, t.Credential).Order(t.SortOrder, t.ID).Find()
 	if err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	if err = resolveConnections(s.Query, connList); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err = fillConnectionStats(s.Query, connList); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
 	return resp.OkWithData(connList)
 }
+
+func (s *ConnectionSrv) SupportedAlgorithms() *resp.Resp {
+	return resp.OkWithData(commonssh.SupportedAlgorithms())
+}
+
+func validateSettings(settings *model.ConnectionSettings) error {
+	if settings.Proxy != "" {
+		proxyURL, err := url.Parse(settings.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+		if !slices.Contains([]string{"socks5", "socks5h", "http"}, proxyURL.Scheme) || proxyURL.Host == "" {
+			return errors.New("proxy must be a socks5://, socks5h:// or http:// URL with a host")
+		}
+		if proxyURL.User != nil {
+			// the settings are stored and listed in plain text
+			return errors.New("proxy username and password belong in a proxy credential, not in the URL")
+		}
+	}
+	if settings.KeepAliveInterval < 0 {
+		return errors.New("keepalive interval cannot be negative")
+	}
+	return commonssh.ValidateAlgorithms(&types.SSHAlgorithms{
+		KeyExchanges:        settings.SSHKeyExchanges,
+		Ciphers:             settings.SSHCiphers,
+		MACs:                settings.SSHMACs,
+		HostKeyAlgorithms:   settings.SSHHostKeyAlgorithms,
+		PublicKeyAlgorithms: settings.SSHPublicKeyAlgorithms,
+	})
+}
+
+// normalizeTags trims tags and attributes and drops empty and duplicate tags.
+// Attribute names must be usable as search fields.
+func normalizeTags(tags []string, attributes map[string]string) ([]string, map[string]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.ContainsFunc(normalized, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	trimmed := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		name = strings.TrimSpace(name)
+		if err := search.ValidateAttribute(name); err != nil {
+			return nil, nil, err
+		}
+		trimmed[name] = strings.TrimSpace(value)
+	}
+	return normalized, trimmed, nil
+}
+
+// uniqueLabel returns the first connection label of the form "label (suffix)",
+// "label (suffix 2)" and so on that no live connection uses.
+func uniqueLabel(tx *query.Query, label, suffix string) (string, error) {
+	t := tx.Connection
+	return uniqueName(label, suffix, func(candidate string) (int64, error) {
+		return t.Where(t.Label.Eq(candidate)).Count()
+	})
+}
+
+// uniqueName returns the first name of the form "name (suffix)", "name (suffix 2)"
+// and so on for which count returns 0. A suffix already on name is replaced, so
+// copies of copies stay readable.
+func uniqueName(name, suffix string, count func(candidate string) (int64, error)) (string, error) {
+	existing := regexp.MustCompile(`^(.*) \(` + regexp.QuoteMeta(suffix) + `(?: \d+)?\)$`)
+	if m := existing.FindStringSubmatch(name); m != nil {
+		name = m[1]
+	}
+	candidate := fmt.Sprintf("%s (%s)", name, suffix)
+	for n := 2; ; n++ {
+		taken, err := count(candidate)
+		if err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%s %d)", name, suffix, n)
+	}
+}
+
+// copyCredential returns an unsaved private copy of the credential with its secrets
+// decrypted, so they are encrypted again under new salts when it is stored.
+func copyCredential(src *model.Credential) (*model.Credential, error) {
+	if err := src.Decrypt(); err != nil {
+		return nil, err
+	}
+	return &model.Credential{
+		Username:          src.Username,
+		AuthMethod:        src.AuthMethod,
+		Password:          src.Password,
+		PrivateKey:        src.PrivateKey,
+		Passphrase:        src.Passphrase,
+		Source:            src.Source,
+		PasswordCommand:   src.PasswordCommand,
+		PrivateKeyCommand: src.PrivateKeyCommand,
+		PassphraseCommand: src.PassphraseCommand,
+		SecretCacheTTL:    src.SecretCacheTTL,
+	}, nil
+}
+
+// sshConfig builds the client configuration for conn, resolving the credential
+// fallback chain from the connection first and then its groups, nearest first.
+// conn must come from FindByID so inherited settings are already resolved.
+func (s *ConnectionSrv) sshConfig(conn *model.Connection) (*commonssh.Config, error) {
+	return s.hopConfig(conn, []uint{conn.ID})
+}
+
+// proxyURL returns the proxy of conn with the username and password of its proxy
+// credential filled in. They only ever exist in memory this way.
+func (s *ConnectionSrv) proxyURL(conn *model.Connection) (string, error) {
+	if conn.Proxy == "" || conn.ProxyCredentialID == nil {
+		return conn.Proxy, nil
+	}
+	t := s.Query.Credential
+	cred, err := t.Where(t.ID.Eq(*conn.ProxyCredentialID)).First()
+	if err != nil {
+		return "", fmt.Errorf("proxy credential: %w", err)
+	}
+	if err = cred.Decrypt(); err != nil {
+		return "", err
+	}
+	if err = s.SecretSrv.Resolve(cred); err != nil {
+		return "", err
+	}
+	proxyURL, err := url.Parse(conn.Proxy)
+	if err != nil {
+		return "", fmt.Errorf("invalid proxy: %w", err)
+	}
+	proxyURL.User = url.UserPassword(cred.Username, cred.Password)
+	return proxyURL.String(), nil
+}
+
+// hopConfig builds the configuration of one host in a jump chain; visited holds the
+// connections already in the chain.
+func (s *ConnectionSrv) hopConfig(conn *model.Connection, visited []uint) (*commonssh.Config, error) {
+	conf, err := s.hopTransport(conn, visited)
+	if err != nil {
+		return nil, err
+	}
+	conf.UseDefaultIdentities = conn.TryDefaultIdentities
+	conf.MaxAuthTries = conn.MaxAuthTries
+	conf.PreferredIdentity = conn.LastAuthIdentity
+
+	var ids []uint
+	if conn.Credential != nil {
+		if err := s.SecretSrv.Resolve(conn.Credential); err != nil {
+			return nil, err
+		}
+		conf.User = conn.Credential.Username
+		conf.AuthMethod = conn.Credential.AuthMethod
+		conf.Password = conn.Credential.Password
+		conf.PrivateKey = conn.Credential.PrivateKey
+		conf.Passphrase = conn.Credential.Passphrase
+		conf.Identities = append(conf.Identities, credentialIdentity(conn.Credential))
+		ids = append(ids, conn.Credential.ID)
+	}
+
+	fallbackIDs := slices.Clone(conn.FallbackCredentialIDs)
+	chain, err := groupChain(s.Query, conn.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range chain {
+		fallbackIDs = append(fallbackIDs, group.FallbackCredentialIDs...)
+		conf.UseDefaultIdentities = conf.UseDefaultIdentities || group.TryDefaultIdentities
+	}
+
+	for _, id := range fallbackIDs {
+		if slices.Contains(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+		t := s.Query.Credential
+		cred, err := t.Where(t.ID.Eq(id)).First()
+		if err != nil {
+			s.Logger.Warn("Skipping fallback credential %d: %v", id, err)
+			continue
+		}
+		if err = cred.Decrypt(); err != nil {
+			return nil, err
+		}
+		if err = s.SecretSrv.Resolve(cred); err != nil {
+			return nil, err
+		}
+		conf.Identities = append(conf.Identities, credentialIdentity(cred))
+	}
+
+	conf.OnAuthenticated = func(identity *commonssh.Identity) {
+		if identity.Key == conn.LastAuthIdentity {
+			return
+		}
+		t := s.Query.Connection
+		if _, err := t.Where(t.ID.Eq(conn.ID)).UpdateSimple(t.LastAuthIdentity.Value(identity.Key)); err != nil {
+			s.Logger.Error("Failed to record successful identity: %v, connID: %d", err, conn.ID)
+		}
+	}
+	return conf, nil
+}
+
+// hopTransport builds the part of a host configuration that does not log into it.
+func (s *ConnectionSrv) hopTransport(conn *model.Connection, visited []uint) (*commonssh.Config, error) {
+	conf := &commonssh.Config{
+		Host:                conn.Host,
+		Port:                conn.Port,
+		KeyExchanges:        conn.SSHKeyExchanges,
+		Ciphers:             conn.SSHCiphers,
+		MACs:                conn.SSHMACs,
+		HostKeyAlgorithms:   conn.SSHHostKeyAlgorithms,
+		PublicKeyAlgorithms: conn.SSHPublicKeyAlgorithms,
+		KeepAlive:           time.Duration(conn.KeepAliveInterval) * time.Second,
+	}
+	var err error
+	if conf.Proxy, err = s.proxyURL(conn); err != nil {
+		return nil, err
+	}
+
+	if conn.JumpConnectionID != nil {
+		if slices.Contains(visited, *conn.JumpConnectionID) {
+			return nil, fmt.Errorf("jump host chain of %s loops back to connection %d", conn.Label, *conn.JumpConnectionID)
+		}
+		jump, err := s.FindByID(*conn.JumpConnectionID)
+		if err != nil {
+			return nil, fmt.Errorf("jump host: %w", err)
+		}
+		if conf.Jump, err = s.hopConfig(jump, append(visited, jump.ID)); err != nil {
+			return nil, err
+		}
+	}
+	return conf, nil
+}
+
+func credentialIdentity(cred *model.Credential) *commonssh.Identity {
+	return &commonssh.Identity{
+		Key:        fmt.Sprintf("credential:%d", cred.ID),
+		User:       cred.Username,
+		AuthMethod: cred.AuthMethod,
+		Password:   cred.Password,
+		PrivateKey: cred.PrivateKey,
+		Passphrase: cred.Passphrase,
+	}
+}
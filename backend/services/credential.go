@@ -1,10 +1,16 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"github.com/Q191/GTerm/backend/consts/messages"
 	"github.com/Q191/GTerm/backend/dal/model"
 	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
 	"github.com/Q191/GTerm/backend/utils/resp"
 	"github.com/google/wire"
 )
@@ -12,24 +18,43 @@ import (
 var CredentialSrvSet = wire.NewSet(wire.Struct(new(CredentialSrv), "*"))
 
 type CredentialSrv struct {
-	Logger initialize.Logger
-	Query  *query.Query
+	Logger      initialize.Logger
+	Query       *query.Query
+	AuditLogSrv *AuditLogSrv
 }
 
 func (s *CredentialSrv) CreateCredential(cred *model.Credential) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
 	t := s.Query.Credential
 	cred.IsCommonCredential = true
 	if err := t.Create(cred); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionCredentialCreate, CredentialID: cred.ID, Target: cred.Label})
 	return resp.OkWithCode(messages.CreateSuccess)
 }
 
 func (s *CredentialSrv) UpdateCredential(cred *model.Credential) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
 	t := s.Query.Credential
 	if _, err := t.Where(t.ID.Eq(cred.ID)).Updates(cred); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	if cred.Source == enums.CredentialSourceCommand {
+		// drop secrets stored before the credential was switched to an external provider
+		if _, err := t.Where(t.ID.Eq(cred.ID)).UpdateSimple(
+			t.PasswordCiphertext.Value(""), t.PasswordSalt.Value(""),
+			t.PrivateKeyCiphertext.Value(""), t.PrivateKeySalt.Value(""),
+			t.PassphraseCiphertext.Value(""), t.PassphraseSalt.Value(""),
+		); err != nil {
+			return resp.FailWithMsg(err.Error())
+		}
+	}
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionCredentialUpdate, CredentialID: cred.ID, Target: cred.Label})
 	return resp.OkWithCode(messages.UpdateSuccess)
 }
 
@@ -42,21 +67,134 @@ func (s *CredentialSrv) ListCredential() *resp.Resp {
 	return resp.OkWithData(credentials)
 }
 
+// DeleteCredential refuses to delete a credential that connections or groups still
+// reference, even from the trash; the usage is returned so the user can reassign
+// them.
 func (s *CredentialSrv) DeleteCredential(id uint) *resp.Resp {
-	t := s.Query.Credential
-	_, err := t.Where(t.ID.Eq(id)).Delete()
+	var usage *types.CredentialUsage
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		var err error
+		if usage, err = credentialUsage(tx, id); err != nil {
+			return err
+		}
+		if usage.InUse() {
+			return nil
+		}
+		return trashCredential(tx, id)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if usage.InUse() {
+		return resp.FailWithCodeAndData(messages.CredentialInUse, usage)
+	}
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionCredentialDelete, CredentialID: id})
+	return resp.OkWithCode(messages.DeleteSuccess)
+}
+
+func (s *CredentialSrv) CredentialUsage(id uint) *resp.Resp {
+	usage, err := credentialUsage(s.Query, id)
 	if err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	return resp.OkWithData(usage)
+}
+
+// ReassignAndDeleteCredential points every connection, group and template using
+// the credential at replacementID and deletes it, all in one transaction.
+func (s *CredentialSrv) ReassignAndDeleteCredential(id, replacementID uint) *resp.Resp {
+	if id == replacementID {
+		return resp.FailWithMsg("replacement must be a different credential")
+	}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		replacement, err := tx.Credential.Where(tx.Credential.ID.Eq(replacementID)).First()
+		if err != nil {
+			return err
+		}
+		if !replacement.IsCommonCredential {
+			return errors.New("replacement must be a common credential")
+		}
+
+		usage, err := credentialUsage(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, item := range usage.Connections {
+			conn, err := tx.Connection.Unscoped().Where(tx.Connection.ID.Eq(item.ID)).First()
+			if err != nil {
+				return err
+			}
+			if item.Primary {
+				conn.CredentialID = &replacementID
+			}
+			if item.Proxy {
+				conn.ProxyCredentialID = &replacementID
+			}
+			conn.FallbackCredentialIDs = replaceCredentialID(conn.FallbackCredentialIDs, id, replacementID)
+			if _, err = tx.Connection.Unscoped().Where(tx.Connection.ID.Eq(conn.ID)).
+				Select(tx.Connection.CredentialID, tx.Connection.ProxyCredentialID, tx.Connection.FallbackCredentialIDs).Updates(conn); err != nil {
+				return err
+			}
+		}
+		for _, item := range usage.Groups {
+			group, err := tx.Group.Unscoped().Where(tx.Group.ID.Eq(item.ID)).First()
+			if err != nil {
+				return err
+			}
+			if item.Primary {
+				group.CredentialID = &replacementID
+			}
+			if item.Proxy {
+				group.ProxyCredentialID = &replacementID
+			}
+			group.FallbackCredentialIDs = replaceCredentialID(group.FallbackCredentialIDs, id, replacementID)
+			if _, err = tx.Group.Unscoped().Where(tx.Group.ID.Eq(group.ID)).
+				Select(tx.Group.CredentialID, tx.Group.ProxyCredentialID, tx.Group.FallbackCredentialIDs).Updates(group); err != nil {
+				return err
+			}
+		}
+		for _, item := range usage.Templates {
+			tmpl, err := tx.ConnectionTemplate.Where(tx.ConnectionTemplate.ID.Eq(item.ID)).First()
+			if err != nil {
+				return err
+			}
+			if item.Primary {
+				tmpl.CredentialID = &replacementID
+			}
+			if item.Proxy {
+				tmpl.ProxyCredentialID = &replacementID
+			}
+			tmpl.FallbackCredentialIDs = replaceCredentialID(tmpl.FallbackCredentialIDs, id, replacementID)
+			if _, err = tx.ConnectionTemplate.Where(tx.ConnectionTemplate.ID.Eq(tmpl.ID)).
+				Select(tx.ConnectionTemplate.CredentialID, tx.ConnectionTemplate.ProxyCredentialID, tx.ConnectionTemplate.FallbackCredentialIDs).Updates(tmpl); err != nil {
+				return err
+			}
+		}
+		s.Logger.Info("Reassigned %d connections, %d groups and %d templates from credential %d to %d",
+			len(usage.Connections), len(usage.Groups), len(usage.Templates), id, replacementID)
+
+		return trashCredential(tx, id)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	s.AuditLogSrv.record(&model.AuditLog{
+		Action:       enums.AuditActionCredentialDelete,
+		CredentialID: id,
+		Detail:       fmt.Sprintf("users reassigned to credential %d", replacementID),
+	})
 	return resp.OkWithCode(messages.DeleteSuccess)
 }
 
 func (s *CredentialSrv) FindCredentialByID(id uint) *resp.Resp {
-	conn, err := s.FindByID(id)
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	cred, err := s.FindByID(id)
 	if err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
-	return resp.OkWithData(conn)
+	// the decrypted secrets leave the backend here, unlike connecting with them
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionCredentialDecrypt, CredentialID: cred.ID, Target: cred.Label})
+	return resp.OkWithData(cred)
 }
 
 func (s *CredentialSrv) FindByID(id uint) (*model.Credential, error) {
@@ -70,3 +208,77 @@ func (s *CredentialSrv) FindByID(id uint) (*model.Credential, error) {
 	}
 	return cred, nil
 }
+
+// credentialUsage finds the connections, groups and templates using the credential
+// directly, as a fallback or for their proxy, including those in the trash.
+func credentialUsage(q *query.Query, id uint) (*types.CredentialUsage, error) {
+	usage := &types.CredentialUsage{
+		CredentialID: id,
+		Connections:  make([]*types.CredentialConnectionUsage, 0),
+		Groups:       make([]*types.CredentialGroupUsage, 0),
+		Templates:    make([]*types.CredentialTemplateUsage, 0),
+	}
+
+	connList, err := q.Connection.Unscoped().Find()
+	if err != nil {
+		return nil, err
+	}
+	for _, conn := range connList {
+		primary := conn.CredentialID != nil && *conn.CredentialID == id
+		proxy := conn.ProxyCredentialID != nil && *conn.ProxyCredentialID == id
+		if primary || proxy || slices.Contains(conn.FallbackCredentialIDs, id) {
+			usage.Connections = append(usage.Connections, &types.CredentialConnectionUsage{
+				ID:      conn.ID,
+				Label:   conn.Label,
+				Host:    conn.Host,
+				Primary: primary,
+				Proxy:   proxy,
+				Trashed: conn.DeletedAt.Valid,
+			})
+		}
+	}
+
+	groups, err := q.Group.Unscoped().Find()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		primary := group.CredentialID != nil && *group.CredentialID == id
+		proxy := group.ProxyCredentialID != nil && *group.ProxyCredentialID == id
+		if primary || proxy || slices.Contains(group.FallbackCredentialIDs, id) {
+			usage.Groups = append(usage.Groups, &types.CredentialGroupUsage{
+				ID:      group.ID,
+				Name:    group.Name,
+				Primary: primary,
+				Proxy:   proxy,
+				Trashed: group.DeletedAt.Valid,
+			})
+		}
+	}
+
+	templates, err := q.ConnectionTemplate.Find()
+	if err != nil {
+		return nil, err
+	}
+	for _, tmpl := range templates {
+		primary := tmpl.CredentialID != nil && *tmpl.CredentialID == id
+		proxy := tmpl.ProxyCredentialID != nil && *tmpl.ProxyCredentialID == id
+		if primary || proxy || slices.Contains(tmpl.FallbackCredentialIDs, id) {
+			usage.Templates = append(usage.Templates, &types.CredentialTemplateUsage{ID: tmpl.ID, Name: tmpl.Name, Primary: primary, Proxy: proxy})
+		}
+	}
+	return usage, nil
+}
+
+func replaceCredentialID(ids []uint, old, replacement uint) []uint {
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == old {
+			id = replacement
+		}
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
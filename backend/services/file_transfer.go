@@ -2,12 +2,14 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
 	"github.com/Q191/GTerm/backend/pkg/sftp"
-	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
 	"github.com/Q191/GTerm/backend/types"
 	"github.com/Q191/GTerm/backend/utils/resp"
 	"github.com/google/wire"
@@ -20,8 +22,11 @@ type FileTransferSrv struct {
 	Logger           initialize.Logger
 	ConnectionSrv    *ConnectionSrv
 	AppContext       *initialize.AppContext
+	AuditLogSrv      *AuditLogSrv
 	SFTPHandler      *sftp.Handler `wire:"-"`
 	SFTPHandlerMutex sync.Mutex    `wire:"-"`
+	// connectionID is the connection the SFTP handler is connected to.
+	connectionID uint `wire:"-"`
 }
 
 func (s *FileTransferSrv) checkSFTPConnection() error {
@@ -72,22 +77,22 @@ func (s *FileTransferSrv) ConnectSFTP(connID uint) *resp.Resp {
 		return resp.FailWithMsg(err.Error())
 	}
 
-	conf := &commonssh.Config{
-		Host:             conn.Host,
-		Port:             conn.Port,
-		User:             conn.Credential.Username,
-		AuthMethod:       conn.Credential.AuthMethod,
-		Password:         conn.Credential.Password,
-		PrivateKey:       conn.Credential.PrivateKey,
-		Passphrase:       conn.Credential.Passphrase,
-		TrustUnknownHost: true,
+	conf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		s.Logger.Error("Failed to build SSH configuration: %v, connID: %d", err, connID)
+		return resp.FailWithMsg(err.Error())
 	}
 
 	if err = s.SFTPHandler.Connect(conf); err != nil {
 		s.Logger.Error("Failed to connect to SFTP server: %v", err)
+		// 主机密钥未确认, 交由前端走与终端相同的确认流程
+		if r := hostKeyConfirmation(err); r != nil {
+			return r
+		}
 		return resp.FailWithMsg(err.Error())
 	}
 
+	s.connectionID = connID
 	s.Logger.Info("SFTP connection successful")
 	return resp.OkWithCode(messages.Connected)
 }
@@ -187,6 +192,13 @@ func (s *FileTransferSrv) UploadFiles(localPaths []string, remotePath string) *r
 				s.Logger.Error("Failed to upload file: %v", err)
 				return resp.FailWithMsg(err.Error())
 			}
+			s.AuditLogSrv.record(&model.AuditLog{
+				Action:       enums.AuditActionFileUpload,
+				ConnectionID: s.connectionID,
+				Target:       remoteFilePath,
+				Detail:       fmt.Sprintf("from %s", localPath),
+				Size:         fileSize,
+			})
 
 			completedFiles++
 		}
@@ -246,6 +258,13 @@ func (s *FileTransferSrv) DownloadFiles(remotePaths []string, localPath string)
 				s.Logger.Error("Failed to download file: %v", err)
 				return resp.FailWithMsg(err.Error())
 			}
+			s.AuditLogSrv.record(&model.AuditLog{
+				Action:       enums.AuditActionFileDownload,
+				ConnectionID: s.connectionID,
+				Target:       remotePath,
+				Detail:       fmt.Sprintf("to %s", localFilePath),
+				Size:         fileSize,
+			})
 
 			completedFiles++
 		}
@@ -1,59 +1,477 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
 	"github.com/Q191/GTerm/backend/consts/messages"
 	"github.com/Q191/GTerm/backend/dal/model"
 	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/search"
 	"github.com/Q191/GTerm/backend/utils/resp"
 	"github.com/google/wire"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
 )
 
 var GroupSrvSet = wire.NewSet(wire.Struct(new(GroupSrv), "*"))
 
+var (
+	errGroupCycle         = errors.New("a group cannot be moved into itself or one of its subgroups")
+	errSmartGroupMembers  = errors.New("smart groups get their connections from their rule and cannot contain connections or subgroups")
+	errSmartGroupDefaults = errors.New("smart groups cannot carry default settings")
+)
+
 type GroupSrv struct {
-	Logger initialize.Logger
-	Query  *query.Query
+	Logger        initialize.Logger
+	Query         *query.Query
+	TunnelRunners *TunnelRunners
 }
 
 func (s *GroupSrv) CreateGroup(group *model.Group) *resp.Resp {
-	t := s.Query.Group
-	if err := t.Create(group); err != nil {
+	if err := validateSettings(&group.ConnectionSettings); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err := validateSmartGroup(group); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		if err := checkStaticGroup(tx, group.ParentID); err != nil {
+			return err
+		}
+		if err := checkGroupName(tx, group.ParentID, group.Name, 0); err != nil {
+			return err
+		}
+		order, err := nextGroupOrder(tx, group.ParentID)
+		if err != nil {
+			return err
+		}
+		group.SortOrder = order
+		return tx.Group.Create(group)
+	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
 	return resp.OkWithCode(messages.CreateSuccess)
 }
 
+// UpdateGroup keeps the position of the group in the tree, use MoveGroup to change it.
+// Every other field is written, so clearing a default makes connections fall back to
+// the parent groups again. A group can only become smart while it is empty.
 func (s *GroupSrv) UpdateGroup(group *model.Group) *resp.Resp {
-	t := s.Query.Group
-	if _, err := t.Where(t.ID.Eq(group.ID)).Updates(group); err != nil {
+	if err := validateSettings(&group.ConnectionSettings); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err := validateSmartGroup(group); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		t := tx.Group
+		old, err := t.Where(t.ID.Eq(group.ID)).First()
+		if err != nil {
+			return err
+		}
+		if group.Rule != "" && old.Rule == "" {
+			if err = checkGroupEmpty(tx, old.ID); err != nil {
+				return err
+			}
+		}
+		if group.Name != old.Name {
+			if err = checkGroupName(tx, old.ParentID, group.Name, old.ID); err != nil {
+				return err
+			}
+		}
+		_, err = t.Where(t.ID.Eq(group.ID)).Select(field.Star).
+			Omit(t.ID, t.CreatedAt, t.DeletedAt, t.ParentID, t.SortOrder).Updates(group)
+		return err
+	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
 	return resp.OkWithCode(messages.UpdateSuccess)
 }
 
-func (s *GroupSrv) DeleteGroup(id uint) *resp.Resp {
+// MoveGroup places the group at index among the children of parentID, or at the top
+// level when parentID is nil.
+func (s *GroupSrv) MoveGroup(id uint, parentID *uint, index int) *resp.Resp {
 	if err := s.Query.Transaction(func(tx *query.Query) error {
-		_, err := tx.Connection.Where(tx.Connection.GroupID.Eq(id)).UpdateSimple(tx.Connection.GroupID.Null())
+		t := tx.Group
+		group, err := t.Where(t.ID.Eq(id)).First()
 		if err != nil {
 			return err
 		}
-		_, err = tx.Group.Where(tx.Group.ID.Eq(id)).Delete()
+		if parentID != nil {
+			groups, err := t.Find()
+			if err != nil {
+				return err
+			}
+			if *parentID == id || slices.Contains(groupDescendants(groups, id), *parentID) {
+				return errGroupCycle
+			}
+			i := slices.IndexFunc(groups, func(g *model.Group) bool { return g.ID == *parentID })
+			if i < 0 {
+				return fmt.Errorf("parent group %d not found", *parentID)
+			}
+			if groups[i].Rule != "" {
+				return errSmartGroupMembers
+			}
+		}
+		if !sameID(group.ParentID, parentID) {
+			if err = checkGroupName(tx, parentID, group.Name, group.ID); err != nil {
+				return err
+			}
+		}
+
+		siblings, err := t.Where(groupParentIs(tx, parentID), t.ID.Neq(id)).Order(t.SortOrder, t.ID).Find()
 		if err != nil {
 			return err
 		}
+		siblings = slices.Insert(siblings, clampIndex(index, len(siblings)), group)
+		for i, sibling := range siblings {
+			if sibling.ID == id {
+				_, err = t.Where(t.ID.Eq(id)).Select(t.ParentID, t.SortOrder).Updates(&model.Group{ParentID: parentID, SortOrder: i})
+			} else {
+				_, err = t.Where(t.ID.Eq(sibling.ID)).UpdateSimple(t.SortOrder.Value(i))
+			}
+			if err != nil {
+				return err
+			}
+		}
 		return nil
 	}); err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
+	return resp.OkWithCode(messages.UpdateSuccess)
+}
+
+// MoveConnection places the connection at index within the group, or among the
+// ungrouped connections when groupID is nil.
+func (s *GroupSrv) MoveConnection(id uint, groupID *uint, index int) *resp.Resp {
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		t := tx.Connection
+		if _, err := t.Where(t.ID.Eq(id)).First(); err != nil {
+			return err
+		}
+		if err := checkStaticGroup(tx, groupID); err != nil {
+			return err
+		}
+
+		do := t.Where(t.ID.Neq(id))
+		if groupID == nil {
+			do = do.Where(t.GroupID.IsNull())
+		} else {
+			do = do.Where(t.GroupID.Eq(*groupID))
+		}
+		siblings, err := do.Order(t.SortOrder, t.ID).Find()
+		if err != nil {
+			return err
+		}
+		ids := make([]uint, 0, len(siblings)+1)
+		for _, sibling := range siblings {
+			ids = append(ids, sibling.ID)
+		}
+		ids = slices.Insert(ids, clampIndex(index, len(ids)), id)
+		for i, connID := range ids {
+			if connID == id {
+				_, err = t.Where(t.ID.Eq(id)).Select(t.GroupID, t.SortOrder).Updates(&model.Connection{GroupID: groupID, SortOrder: i})
+			} else {
+				_, err = t.Where(t.ID.Eq(connID)).UpdateSimple(t.SortOrder.Value(i))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.UpdateSuccess)
+}
+
+// DeleteGroup either moves the subgroups and connections of the group up to its
+// parent, or deletes the whole subtree including the connections in it. Either way
+// the deleted groups and connections go to the trash as one item. An empty mode
+// moves them up, as deleting a group did before groups could nest. The tunnels of
+// deleted connections are stopped.
+func (s *GroupSrv) DeleteGroup(id uint, mode enums.GroupDeleteMode) *resp.Resp {
+	item := &model.TrashItem{Kind: enums.TrashKindGroup, GroupIDs: []uint{id}}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		group, err := tx.Group.Where(tx.Group.ID.Eq(id)).First()
+		if err != nil {
+			return err
+		}
+		item.Name = group.Name
+		switch mode {
+		case enums.GroupDeleteModeReparent, "":
+			err = reparentGroupChildren(tx, group)
+		case enums.GroupDeleteModeSubtree:
+			err = trashGroupSubtree(tx, group, item)
+		default:
+			err = fmt.Errorf("unsupported delete mode: %s", mode)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Group.Where(tx.Group.ID.In(item.GroupIDs...)).Delete(); err != nil {
+			return err
+		}
+		return tx.TrashItem.Create(item)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	s.TunnelRunners.stop(item.TunnelIDs...)
 	return resp.OkWithCode(messages.DeleteSuccess)
 }
 
+// ListGroupConnections returns the connections in the group, evaluating the rule of
+// smart groups.
+func (s *GroupSrv) ListGroupConnections(id uint) *resp.Resp {
+	cond, err := groupMembers(s.Query, id)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	t := s.Query.Connection
+	connList, err := t.Preload(t.Metadata, t.Credential).Where(cond).Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err = resolveConnections(s.Query, connList); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(connList)
+}
+
+// ListGroup returns every group as a flat list, ordered within each parent.
 func (s *GroupSrv) ListGroup() *resp.Resp {
 	t := s.Query.Group
-	groups, err := t.Find()
+	groups, err := t.Order(t.SortOrder, t.ID).Find()
 	if err != nil {
 		return resp.FailWithMsg(err.Error())
 	}
 	return resp.OkWithData(groups)
 }
+
+// ListGroupTree returns the top level groups with their subgroups in Children.
+func (s *GroupSrv) ListGroupTree() *resp.Resp {
+	t := s.Query.Group
+	groups, err := t.Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(groupTree(groups))
+}
+
+func groupTree(groups []*model.Group) []*model.Group {
+	byID := make(map[uint]*model.Group, len(groups))
+	for _, group := range groups {
+		group.Children = make([]*model.Group, 0)
+		byID[group.ID] = group
+	}
+	roots := make([]*model.Group, 0)
+	for _, group := range groups {
+		if group.ParentID != nil {
+			if parent, ok := byID[*group.ParentID]; ok {
+				parent.Children = append(parent.Children, group)
+				continue
+			}
+		}
+		roots = append(roots, group)
+	}
+	return roots
+}
+
+// groupDescendants returns the IDs of every group below id, nearest first.
+func groupDescendants(groups []*model.Group, id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, group := range groups {
+		if group.ParentID != nil {
+			children[*group.ParentID] = append(children[*group.ParentID], group.ID)
+		}
+	}
+	var ids []uint
+	queue := []uint{id}
+	for len(queue) > 0 {
+		next := children[queue[0]]
+		queue = append(queue[1:], next...)
+		ids = append(ids, next...)
+	}
+	return ids
+}
+
+func reparentGroupChildren(tx *query.Query, group *model.Group) error {
+	base, err := nextGroupOrder(tx, group.ParentID)
+	if err != nil {
+		return err
+	}
+	children, err := tx.Group.Where(tx.Group.ParentID.Eq(group.ID)).Order(tx.Group.SortOrder, tx.Group.ID).Find()
+	if err != nil {
+		return err
+	}
+	for i, child := range children {
+		if err = checkGroupName(tx, group.ParentID, child.Name, child.ID); err != nil {
+			return err
+		}
+		if _, err = tx.Group.Where(tx.Group.ID.Eq(child.ID)).Select(tx.Group.ParentID, tx.Group.SortOrder).
+			Updates(&model.Group{ParentID: group.ParentID, SortOrder: base + i}); err != nil {
+			return err
+		}
+	}
+
+	t := tx.Connection
+	base, err = nextConnectionOrder(tx, group.ParentID)
+	if err != nil {
+		return err
+	}
+	conns, err := t.Where(t.GroupID.Eq(group.ID)).Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return err
+	}
+	for i, conn := range conns {
+		if _, err = t.Where(t.ID.Eq(conn.ID)).Select(t.GroupID, t.SortOrder).
+			Updates(&model.Connection{GroupID: group.ParentID, SortOrder: base + i}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trashGroupSubtree adds the subgroups of group and every connection below it to
+// the trash item. The caller deletes the groups.
+func trashGroupSubtree(tx *query.Query, group *model.Group, item *model.TrashItem) error {
+	groups, err := tx.Group.Find()
+	if err != nil {
+		return err
+	}
+	item.GroupIDs = append([]uint{group.ID}, groupDescendants(groups, group.ID)...)
+	t := tx.Connection
+	conns, err := t.Where(t.GroupID.In(item.GroupIDs...)).Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return err
+	}
+	for _, conn := range conns {
+		if err = trashConnection(tx, conn, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupMembers returns the condition selecting the connections of a group. Smart
+// groups are evaluated on every call so they always reflect the current connections.
+func groupMembers(tx *query.Query, groupID uint) (gen.Condition, error) {
+	group, err := tx.Group.Where(tx.Group.ID.Eq(groupID)).First()
+	if err != nil {
+		return nil, fmt.Errorf("group: %w", err)
+	}
+	if group.Rule == "" {
+		return tx.Connection.GroupID.Eq(group.ID), nil
+	}
+	expr, err := search.Parse(group.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("rule of smart group %q: %w", group.Name, err)
+	}
+	ids, err := searchConnectionIDs(tx, expr)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Connection.ID.In(ids...), nil
+}
+
+func validateSmartGroup(group *model.Group) error {
+	if group.Rule == "" {
+		return nil
+	}
+	if _, err := search.Parse(group.Rule); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+	if group.CredentialID != nil || len(group.FallbackCredentialIDs) > 0 || group.TryDefaultIdentities ||
+		!reflect.ValueOf(group.ConnectionSettings).IsZero() {
+		return errSmartGroupDefaults
+	}
+	return nil
+}
+
+// checkStaticGroup makes sure connections and subgroups are only put into existing
+// groups that are not smart. A nil ID stands for the top level.
+func checkStaticGroup(tx *query.Query, groupID *uint) error {
+	if groupID == nil {
+		return nil
+	}
+	group, err := tx.Group.Where(tx.Group.ID.Eq(*groupID)).First()
+	if err != nil {
+		return fmt.Errorf("group: %w", err)
+	}
+	if group.Rule != "" {
+		return errSmartGroupMembers
+	}
+	return nil
+}
+
+func checkGroupEmpty(tx *query.Query, id uint) error {
+	conns, err := tx.Connection.Where(tx.Connection.GroupID.Eq(id)).Count()
+	if err != nil {
+		return err
+	}
+	groups, err := tx.Group.Where(tx.Group.ParentID.Eq(id)).Count()
+	if err != nil {
+		return err
+	}
+	if conns > 0 || groups > 0 {
+		return errSmartGroupMembers
+	}
+	return nil
+}
+
+// checkGroupName keeps names unique among siblings. The unique index cannot do this
+// for top level groups because SQLite treats every NULL parent as distinct.
+func checkGroupName(tx *query.Query, parentID *uint, name string, excludeID uint) error {
+	t := tx.Group
+	count, err := t.Where(groupParentIs(tx, parentID), t.Name.Eq(name), t.ID.Neq(excludeID)).Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("group %q already exists at this level", name)
+	}
+	return nil
+}
+
+func nextGroupOrder(tx *query.Query, parentID *uint) (int, error) {
+	t := tx.Group
+	var order int
+	err := t.Where(groupParentIs(tx, parentID)).Select(t.SortOrder.Max().IfNull(-1)).Scan(&order)
+	return order + 1, err
+}
+
+func nextConnectionOrder(tx *query.Query, groupID *uint) (int, error) {
+	t := tx.Connection
+	do := t.Where()
+	if groupID == nil {
+		do = do.Where(t.GroupID.IsNull())
+	} else {
+		do = do.Where(t.GroupID.Eq(*groupID))
+	}
+	var order int
+	err := do.Select(t.SortOrder.Max().IfNull(-1)).Scan(&order)
+	return order + 1, err
+}
+
+func groupParentIs(tx *query.Query, parentID *uint) gen.Condition {
+	if parentID == nil {
+		return tx.Group.ParentID.IsNull()
+	}
+	return tx.Group.ParentID.Eq(*parentID)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func clampIndex(index, length int) int {
+	return max(0, min(index, length))
+}
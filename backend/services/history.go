@@ -0,0 +1,262 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultHistoryLimit = 100
+	defaultRecentLimit  = 10
+)
+
+var HistorySrvSet = wire.NewSet(wire.Struct(new(HistorySrv), "*"))
+
+// HistorySrv records every terminal session with how long it lasted and why it
+// ended. Sessions are keyed by their websocket while they are open.
+type HistorySrv struct {
+	Logger         initialize.Logger
+	Query          *query.Query
+	PreferencesSrv *PreferencesSrv
+	mutex          sync.Mutex                                `wire:"-"`
+	active         map[*websocket.Conn]*model.SessionHistory `wire:"-"`
+}
+
+type historyPage struct {
+	Total int64                   `json:"total"`
+	Items []*model.SessionHistory `json:"items"`
+}
+
+// connectionStats is the session history summary of one connection.
+type connectionStats struct {
+	ConnectionID    uint
+	LastConnectedAt time.Time
+	ConnectCount    int64
+}
+
+// ListConnectionHistory returns the sessions of a connection, newest first.
+func (s *HistorySrv) ListConnectionHistory(connID uint, limit, offset int) *resp.Resp {
+	t := s.Query.SessionHistory
+	do := t.Where(t.ConnectionID.Eq(connID))
+	total, err := do.Count()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	items, err := do.Order(t.ID.Desc()).Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(&historyPage{Total: total, Items: items})
+}
+
+// RecentConnections returns the most recently connected connections, most recent
+// first, with LastConnectedAt and ConnectCount filled.
+func (s *HistorySrv) RecentConnections(limit int) *resp.Resp {
+	if limit <= 0 {
+		limit = defaultRecentLimit
+	}
+	stats, err := sessionStats(s.Query)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	ids := make([]uint, len(stats))
+	for i, stat := range stats {
+		ids[i] = stat.ConnectionID
+	}
+	t := s.Query.Connection
+	found, err := t.Preload(t.Metadata, t.Credential).Where(t.ID.In(ids...)).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	byID := make(map[uint]*model.Connection, len(found))
+	for _, conn := range found {
+		byID[conn.ID] = conn
+	}
+	// stats are ordered by recency, deleted connections are skipped
+	connList := make([]*model.Connection, 0, limit)
+	for _, stat := range stats {
+		if conn, ok := byID[stat.ConnectionID]; ok && len(connList) < limit {
+			connList = append(connList, conn)
+		}
+	}
+	if err = resolveConnections(s.Query, connList); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err = fillConnectionStats(s.Query, connList); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(connList)
+}
+
+// Prune applies the retention limits to the whole history. It is called once at
+// startup, afterwards each finished session prunes its own connection.
+func (s *HistorySrv) Prune() {
+	if err := s.prune(nil); err != nil {
+		s.Logger.Error("Failed to prune session history: %v", err)
+	}
+}
+
+// start records the attempt to open a session for the connection.
+func (s *HistorySrv) start(ws *websocket.Conn, connID uint) {
+	entry := &model.SessionHistory{ConnectionID: connID, StartedAt: time.Now()}
+	t := s.Query.Connection
+	if conn, err := t.Where(t.ID.Eq(connID)).First(); err == nil {
+		entry.Label, entry.Host, entry.Protocol = conn.Label, conn.Host, conn.ConnProtocol
+	}
+	if err := s.Query.SessionHistory.Create(entry); err != nil {
+		s.Logger.Error("Failed to record session start: %v, connID: %d", err, connID)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.active == nil {
+		s.active = make(map[*websocket.Conn]*model.SessionHistory)
+	}
+	s.active[ws] = entry
+}
+
+// connected marks the session as established. After a host key prompt the session
+// reconnects, the first successful connect counts.
+func (s *HistorySrv) connected(ws *websocket.Conn) {
+	s.mutex.Lock()
+	entry, ok := s.active[ws]
+	s.mutex.Unlock()
+	if !ok || entry.ConnectedAt != nil {
+		return
+	}
+	now := time.Now()
+	entry.ConnectedAt = &now
+	t := s.Query.SessionHistory
+	if _, err := t.Where(t.ID.Eq(entry.ID)).Update(t.ConnectedAt, now); err != nil {
+		s.Logger.Error("Failed to record session connect: %v, connID: %d", err, entry.ConnectionID)
+	}
+}
+
+// finish records the end of the session. Only the first reason is kept, as a
+// session may be closed more than once on the way out.
+func (s *HistorySrv) finish(ws *websocket.Conn, reason string) {
+	s.mutex.Lock()
+	entry, ok := s.active[ws]
+	delete(s.active, ws)
+	s.mutex.Unlock()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	entry.EndedAt = &now
+	entry.ExitReason = reason
+	if entry.ConnectedAt != nil {
+		entry.Duration = int64(now.Sub(*entry.ConnectedAt).Seconds())
+	}
+	t := s.Query.SessionHistory
+	if _, err := t.Where(t.ID.Eq(entry.ID)).Select(t.EndedAt, t.ExitReason, t.Duration).Updates(entry); err != nil {
+		s.Logger.Error("Failed to record session end: %v, connID: %d", err, entry.ConnectionID)
+	}
+	if err := s.prune(&entry.ConnectionID); err != nil {
+		s.Logger.Error("Failed to prune session history: %v, connID: %d", err, entry.ConnectionID)
+	}
+}
+
+// prune deletes sessions older than the retention period and the oldest sessions
+// beyond the per connection limit, for one connection or all when connID is nil.
+func (s *HistorySrv) prune(connID *uint) error {
+	prefs, err := s.PreferencesSrv.preferences()
+	if err != nil {
+		return err
+	}
+	t := s.Query.SessionHistory
+	if prefs.HistoryRetentionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -prefs.HistoryRetentionDays)
+		if _, err = t.Where(t.StartedAt.Lt(cutoff)).Delete(); err != nil {
+			return err
+		}
+	}
+	if prefs.HistoryLimit <= 0 {
+		return nil
+	}
+
+	var connIDs []uint
+	if connID != nil {
+		connIDs = []uint{*connID}
+	} else if err = t.Distinct(t.ConnectionID).Pluck(t.ConnectionID, &connIDs); err != nil {
+		return err
+	}
+	for _, id := range connIDs {
+		var keep []uint
+		if err = t.Where(t.ConnectionID.Eq(id)).Order(t.ID.Desc()).Limit(prefs.HistoryLimit).
+			Pluck(t.ID, &keep); err != nil {
+			return err
+		}
+		if len(keep) < prefs.HistoryLimit {
+			continue
+		}
+		if _, err = t.Where(t.ConnectionID.Eq(id), t.ID.NotIn(keep...)).Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionStats summarizes the established sessions per connection, most recently
+// connected first.
+func sessionStats(q *query.Query) ([]*connectionStats, error) {
+	// SQLite returns MAX over a time column as text, so the latest row is found by ID
+	var rows []struct {
+		ConnectionID uint
+		LastID       uint
+		ConnectCount int64
+	}
+	t := q.SessionHistory
+	if err := t.Select(t.ConnectionID, t.ID.Max().As("last_id"), t.ID.Count().As("connect_count")).
+		Where(t.ConnectedAt.IsNotNull()).Group(t.ConnectionID).Scan(&rows); err != nil {
+		return nil, err
+	}
+	lastIDs := make([]uint, len(rows))
+	counts := make(map[uint]int64, len(rows))
+	for i, row := range rows {
+		lastIDs[i] = row.LastID
+		counts[row.ConnectionID] = row.ConnectCount
+	}
+	latest, err := t.Where(t.ID.In(lastIDs...)).Order(t.ConnectedAt.Desc()).Find()
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*connectionStats, len(latest))
+	for i, entry := range latest {
+		stats[i] = &connectionStats{
+			ConnectionID:    entry.ConnectionID,
+			LastConnectedAt: *entry.ConnectedAt,
+			ConnectCount:    counts[entry.ConnectionID],
+		}
+	}
+	return stats, nil
+}
+
+func fillConnectionStats(q *query.Query, connList []*model.Connection) error {
+	stats, err := sessionStats(q)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*connectionStats, len(stats))
+	for _, stat := range stats {
+		byID[stat.ConnectionID] = stat
+	}
+	for _, conn := range connList {
+		if stat, ok := byID[conn.ID]; ok {
+			lastConnectedAt := stat.LastConnectedAt
+			conn.LastConnectedAt = &lastConnectedAt
+			conn.ConnectCount = stat.ConnectCount
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+var KnownHostsSrvSet = wire.NewSet(wire.Struct(new(KnownHostsSrv), "*"))
+
+type KnownHostsSrv struct {
+	Logger        initialize.Logger
+	Query         *query.Query
+	ConnectionSrv *ConnectionSrv
+	AuditLogSrv   *AuditLogSrv
+}
+
+func (s *KnownHostsSrv) ListKnownHosts(query string) *resp.Resp {
+	entries, err := commonssh.ListKnownHosts(query)
+	if err != nil {
+		s.Logger.Error("Failed to read known_hosts: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(entries)
+}
+
+func (s *KnownHostsSrv) RemoveKnownHost(host string, port uint) *resp.Resp {
+	removed, err := commonssh.RemoveKnownHost(host, port)
+	if err != nil {
+		s.Logger.Error("Failed to remove known host %s:%d: %v", host, port, err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Removed %d known_hosts entries for %s:%d", removed, host, port)
+	return resp.OkWithCodeAndData(messages.KnownHostRemoveSuccess, removed)
+}
+
+func (s *KnownHostsSrv) RemoveKnownHostLine(line int) *resp.Resp {
+	removed, err := commonssh.RemoveKnownHostLine(line)
+	if err != nil {
+		s.Logger.Error("Failed to remove known_hosts line %d: %v", line, err)
+		return resp.FailWithMsg(err.Error())
+	}
+	if removed == 0 {
+		return resp.FailWithMsg(fmt.Sprintf("no known_hosts entry on line %d", line))
+	}
+	return resp.OkWithCodeAndData(messages.KnownHostRemoveSuccess, removed)
+}
+
+// ScanConnection fetches the key the server currently presents and how it compares
+// with known_hosts, so the user can check it before re-pinning.
+func (s *KnownHostsSrv) ScanConnection(connID uint) *resp.Resp {
+	conn, conf, err := s.ConnectionSrv.transportConfig(connID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	fingerprint, status, err := commonssh.ScanHostKey(conf, s.Logger)
+	if err != nil {
+		s.Logger.Error("Failed to scan host key of %s:%d: %v", conf.Host, conf.Port, err)
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(&types.HostKeyScanResult{
+		ConnectionID: conn.ID,
+		Label:        conn.Label,
+		Host:         fmt.Sprintf("%s:%d", conf.Host, conf.Port),
+		Fingerprint:  fingerprint,
+		Status:       status,
+	})
+}
+
+// TrustConnection pins the key of a host that is not in known_hosts yet. It backs
+// the confirmation prompt of connections that do not go through the terminal.
+func (s *KnownHostsSrv) TrustConnection(connID uint, fingerprint string) *resp.Resp {
+	conn, conf, err := s.ConnectionSrv.transportConfig(connID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	host := fmt.Sprintf("%s:%d", conf.Host, conf.Port)
+	if err = commonssh.AddFingerprint(conf, host, fingerprint, s.Logger); err != nil {
+		s.Logger.Error("Failed to trust host key of %s: %v", host, err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionHostKeyAccept, ConnectionID: conn.ID, Target: host, Detail: fingerprint})
+	return resp.OkWithCode(messages.KnownHostTrustSuccess)
+}
+
+// RepinConnection replaces the pinned key of a connection with the current one. The
+// fingerprint confirmed by the user must still match what the server presents.
+func (s *KnownHostsSrv) RepinConnection(connID uint, fingerprint string) *resp.Resp {
+	conn, conf, err := s.ConnectionSrv.transportConfig(connID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	host := fmt.Sprintf("%s:%d", conf.Host, conf.Port)
+	if err = commonssh.ReplaceFingerprint(conf, host, fingerprint, s.Logger); err != nil {
+		s.Logger.Error("Failed to re-pin host key of %s: %v", host, err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.AuditLogSrv.record(&model.AuditLog{
+		Action:       enums.AuditActionHostKeyAccept,
+		ConnectionID: conn.ID,
+		Target:       host,
+		Detail:       fmt.Sprintf("%s, replaced changed key", fingerprint),
+	})
+	return resp.OkWithCode(messages.KnownHostRepinSuccess)
+}
+
+// ScanAndPinGroup pins the keys of every SSH connection in the group whose host is
+// not yet known. Hosts whose key changed are reported and left untouched.
+func (s *KnownHostsSrv) ScanAndPinGroup(groupID uint) *resp.Resp {
+	members, err := groupMembers(s.Query, groupID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	t := s.Query.Connection
+	connList, err := t.Where(members, t.ConnProtocol.Eq(string(enums.SSH))).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+
+	results := make([]*types.HostKeyScanResult, 0, len(connList))
+	for _, item := range connList {
+		result := &types.HostKeyScanResult{ConnectionID: item.ID, Label: item.Label}
+		results = append(results, result)
+
+		conn, conf, err := s.ConnectionSrv.transportConfig(item.ID)
+		if err != nil {
+			result.Status, result.Error = commonssh.HostKeyScanFailed, err.Error()
+			continue
+		}
+		result.Host = fmt.Sprintf("%s:%d", conf.Host, conf.Port)
+		result.Fingerprint, result.Status, err = commonssh.PinHostKey(conf, s.Logger)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		if result.Status == commonssh.HostKeyScanPinned {
+			s.AuditLogSrv.record(&model.AuditLog{
+				Action:       enums.AuditActionHostKeyAccept,
+				ConnectionID: conn.ID,
+				Target:       result.Host,
+				Detail:       fmt.Sprintf("%s, pinned by group scan", result.Fingerprint),
+			})
+		}
+	}
+	s.Logger.Info("Scanned host keys of %d connections in group %d", len(results), groupID)
+	return resp.OkWithCodeAndData(messages.KnownHostScanSuccess, results)
+}
+
+// hostKeyConfirmation turns unconfirmed host key errors into responses carrying the
+// data the frontend needs to ask the user, or returns nil for any other error.
+func hostKeyConfirmation(err error) *resp.Resp {
+	var fingerprintErr *types.FingerprintError
+	var changedErr *types.HostKeyChangedError
+	switch {
+	case errors.As(err, &fingerprintErr):
+		return resp.FailWithCodeAndData(messages.HostKeyUnknown, fingerprintErr)
+	case errors.As(err, &changedErr):
+		return resp.FailWithCodeAndData(messages.HostKeyChanged, changedErr)
+	}
+	return nil
+}
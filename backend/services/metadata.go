@@ -6,7 +6,6 @@ import (
 	"github.com/Q191/GTerm/backend/initialize"
 	"github.com/Q191/GTerm/backend/pkg/exec"
 	"github.com/Q191/GTerm/backend/pkg/metadata"
-	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
 	"github.com/google/wire"
 	"go.uber.org/zap"
 )
@@ -14,23 +13,20 @@ import (
 var MetadataSrvSet = wire.NewSet(wire.Struct(new(MetadataSrv), "*"))
 
 type MetadataSrv struct {
-	Logger initialize.Logger
-	Query  *query.Query
+	Logger        initialize.Logger
+	Query         *query.Query
+	ConnectionSrv *ConnectionSrv
 }
 
 func (s *MetadataSrv) UpdateByConnection(conn *model.Connection) {
 	t := s.Query.Metadata
 
-	config := &commonssh.Config{
-		Host:             conn.Host,
-		Port:             conn.Port,
-		User:             conn.Credential.Username,
-		AuthMethod:       conn.Credential.AuthMethod,
-		Password:         conn.Credential.Password,
-		PrivateKey:       conn.Credential.PrivateKey,
-		Passphrase:       conn.Credential.Passphrase,
-		TrustUnknownHost: true,
+	config, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		s.Logger.Error("failed to build ssh config", zap.Error(err))
+		return
 	}
+	// 后台采集无法询问用户, 未确认的主机密钥直接拒绝
 	client, err := exec.NewExec(config, s.Logger)
 	if err != nil {
 		s.Logger.Error("failed to create ssh client", zap.Error(err))
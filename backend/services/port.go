@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/exec"
+	"github.com/Q191/GTerm/backend/pkg/tunnel"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+var PortSrvSet = wire.NewSet(wire.Struct(new(PortSrv), "*"))
+
+// PortSrv discovers the TCP ports a host listens on and forwards them locally.
+// Results are cached per connection until refreshed.
+type PortSrv struct {
+	Logger        initialize.Logger
+	ConnectionSrv *ConnectionSrv
+	TunnelSrv     *TunnelSrv
+	cache         map[uint]*types.ListeningPortList `wire:"-"`
+	mutex         sync.RWMutex                      `wire:"-"`
+}
+
+func (s *PortSrv) ListListeningPorts(connID uint) *resp.Resp {
+	s.mutex.RLock()
+	list, ok := s.cache[connID]
+	s.mutex.RUnlock()
+	if ok {
+		return resp.OkWithData(list)
+	}
+	return s.RefreshListeningPorts(connID)
+}
+
+func (s *PortSrv) RefreshListeningPorts(connID uint) *resp.Resp {
+	list, err := s.discover(connID)
+	if err != nil {
+		s.Logger.Error("Failed to discover listening ports, connID: %d, error: %v", connID, err)
+		if r := hostKeyConfirmation(err); r != nil {
+			return r
+		}
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(list)
+}
+
+// ForwardListeningPort starts a local forward to a discovered port. An empty bind
+// listens on the same port on the loopback interface.
+func (s *PortSrv) ForwardListeningPort(connID uint, bind string, remoteBind string, port uint16) *resp.Resp {
+	s.mutex.RLock()
+	list, ok := s.cache[connID]
+	s.mutex.RUnlock()
+	if !ok {
+		return resp.FailWithMsg("listening ports have not been discovered")
+	}
+	for _, item := range list.Ports {
+		if item.Port != port || item.Bind != remoteBind {
+			continue
+		}
+		if bind == "" {
+			bind = fmt.Sprintf("127.0.0.1:%d", port)
+		}
+		return s.TunnelSrv.StartLocalForward(connID, bind, tunnel.ForwardTarget(item))
+	}
+	return resp.FailWithMsg(fmt.Sprintf("port %s:%d is not listening", remoteBind, port))
+}
+
+func (s *PortSrv) discover(connID uint) (*types.ListeningPortList, error) {
+	conn, err := s.ConnectionSrv.FindByID(connID)
+	if err != nil {
+		return nil, err
+	}
+	if conn.ConnProtocol != enums.SSH {
+		return nil, errors.New("port discovery requires an SSH connection")
+	}
+	conf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := exec.NewExec(conf, s.Logger)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ports, err := tunnel.DiscoverListeningPorts(exec.New(client))
+	if err != nil {
+		return nil, err
+	}
+	list := &types.ListeningPortList{
+		ConnectionID: connID,
+		Ports:        ports,
+		ScannedAt:    time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cache == nil {
+		s.cache = make(map[uint]*types.ListeningPortList)
+	}
+	s.cache[connID] = list
+	s.Logger.Info("Discovered %d listening ports, connID: %d", len(ports), connID)
+	return list, nil
+}
@@ -1,19 +1,66 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"time"
 
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
 	"github.com/Q191/GTerm/backend/initialize"
 	"github.com/Q191/GTerm/backend/pkg/base"
+	"github.com/Q191/GTerm/backend/utils/resp"
 	"github.com/google/wire"
+	"gorm.io/gorm"
 )
 
 var PreferencesSrvSet = wire.NewSet(wire.Struct(new(PreferencesSrv), "*"))
 
+const preferencesID = 1
+
 type PreferencesSrv struct {
 	Logger initialize.Logger
+	Query  *query.Query
+}
+
+func defaultPreferences() *model.Preferences {
+	return &model.Preferences{
+		ID:                   preferencesID,
+		HistoryRetentionDays: 90,
+		HistoryLimit:         500,
+		TrashRetentionDays:   30,
+	}
+}
+
+func (s *PreferencesSrv) GetPreferences() *resp.Resp {
+	prefs, err := s.preferences()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(prefs)
+}
+
+func (s *PreferencesSrv) UpdatePreferences(prefs *model.Preferences) *resp.Resp {
+	if prefs.HistoryRetentionDays < 0 || prefs.HistoryLimit < 0 || prefs.TrashRetentionDays < 0 {
+		return resp.FailWithMsg("retention limits cannot be negative")
+	}
+	prefs.ID = preferencesID
+	if err := s.Query.Preferences.Save(prefs); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.UpdateSuccess)
+}
+
+// preferences returns the stored settings, or the defaults until they are first saved.
+func (s *PreferencesSrv) preferences() (*model.Preferences, error) {
+	t := s.Query.Preferences
+	prefs, err := t.Where(t.ID.Eq(preferencesID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultPreferences(), nil
+	}
+	return prefs, err
 }
 
 func (s *PreferencesSrv) Version() string {
@@ -11,4 +11,18 @@ var ProviderSet = wire.NewSet(
 	TerminalSrvSet,
 	WebsocketSrvSet,
 	FileTransferSrvSet,
+	SecurityAuditSrvSet,
+	TraceSrvSet,
+	SessionSrvSet,
+	KnownHostsSrvSet,
+	TunnelSrvSet,
+	TunnelRunnersSet,
+	PortSrvSet,
+	VaultSrvSet,
+	SecretSrvSet,
+	RotationSrvSet,
+	AuditLogSrvSet,
+	TemplateSrvSet,
+	HistorySrvSet,
+	TrashSrvSet,
 )
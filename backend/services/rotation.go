@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/exec"
+	"github.com/Q191/GTerm/backend/pkg/rotate"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+const rotationParallelism = 8
+
+var RotationSrvSet = wire.NewSet(wire.Struct(new(RotationSrv), "*"))
+
+// RotationSrv changes the password of a shared credential on many hosts at once.
+// The stored credential is only updated when every host accepted the new password;
+// otherwise the hosts that did change are rolled back to the old one.
+type RotationSrv struct {
+	Logger        initialize.Logger
+	Query         *query.Query
+	ConnectionSrv *ConnectionSrv
+	CredentialSrv *CredentialSrv
+	AuditLogSrv   *AuditLogSrv
+}
+
+func (s *RotationSrv) RotatePassword(req *types.RotationRequest) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	cred, err := s.CredentialSrv.FindByID(req.CredentialID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if cred.AuthMethod != enums.Password {
+		return resp.FailWithMsg("only password credentials can be rotated")
+	}
+	if cred.Source == enums.CredentialSourceCommand {
+		return resp.FailWithMsg("credentials from external secret providers must be rotated in the provider")
+	}
+	if len(req.ConnectionIDs) == 0 {
+		return resp.FailWithMsg("no connections selected")
+	}
+
+	newPassword := req.NewPassword
+	if newPassword == "" {
+		if newPassword, err = rotate.GeneratePassword(req.PasswordLength); err != nil {
+			return resp.FailWithMsg(err.Error())
+		}
+	} else if err = rotate.ValidatePassword(newPassword); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if newPassword == cred.Password {
+		return resp.FailWithMsg("new password must differ from the current one")
+	}
+
+	report := &types.RotationReport{CredentialID: cred.ID}
+	var changed []*rotationHost
+	for _, host := range s.rotateHosts(cred, req.ConnectionIDs, newPassword) {
+		report.Hosts = append(report.Hosts, host.result)
+		if host.changed {
+			changed = append(changed, host)
+		}
+	}
+
+	allVerified := true
+	for _, result := range report.Hosts {
+		if result.Status != enums.RotationStatusVerified {
+			allVerified = false
+			break
+		}
+	}
+
+	if allVerified {
+		cred.Password = newPassword
+		t := s.Query.Credential
+		if _, err = t.Where(t.ID.Eq(cred.ID)).Updates(cred); err != nil {
+			s.Logger.Error("Failed to store rotated password for credential %d: %v", cred.ID, err)
+			// the hosts already use the new password, so they have to go back
+			allVerified = false
+			for _, host := range changed {
+				host.result.Error = fmt.Sprintf("failed to store new password: %v", err)
+			}
+		} else {
+			report.CredentialUpdated = true
+			s.AuditLogSrv.record(&model.AuditLog{
+				Action:       enums.AuditActionCredentialUpdate,
+				CredentialID: cred.ID,
+				Target:       cred.Label,
+				Detail:       fmt.Sprintf("password rotated on %d hosts", len(report.Hosts)),
+			})
+		}
+	}
+	if !allVerified {
+		s.rollback(cred, changed, newPassword)
+	}
+
+	s.Logger.Info("Password rotation of credential %d finished, %d hosts, updated: %v",
+		cred.ID, len(report.Hosts), report.CredentialUpdated)
+	return resp.OkWithCodeAndData(messages.RotationFinished, report)
+}
+
+type rotationHost struct {
+	conn    *model.Connection
+	result  *types.RotationHostResult
+	changed bool
+}
+
+func (s *RotationSrv) rotateHosts(cred *model.Credential, connIDs []uint, newPassword string) []*rotationHost {
+	hosts := make([]*rotationHost, len(connIDs))
+	sem := make(chan struct{}, rotationParallelism)
+	var wg sync.WaitGroup
+	for i, id := range connIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			hosts[i] = s.rotateHost(cred, id, newPassword)
+		}()
+	}
+	wg.Wait()
+	return hosts
+}
+
+func (s *RotationSrv) rotateHost(cred *model.Credential, connID uint, newPassword string) *rotationHost {
+	host := &rotationHost{result: &types.RotationHostResult{ConnectionID: connID, Status: enums.RotationStatusFailed}}
+	conn, err := s.ConnectionSrv.FindByID(connID)
+	if err != nil {
+		host.result.Error = err.Error()
+		return host
+	}
+	host.conn = conn
+	host.result.Label = conn.Label
+	host.result.Host = fmt.Sprintf("%s:%d", conn.Host, conn.Port)
+	if conn.Metadata != nil {
+		host.result.Vendor = conn.Metadata.Vendor
+	}
+	if conn.ConnProtocol != enums.SSH {
+		host.result.Status = enums.RotationStatusSkipped
+		host.result.Error = "not an SSH connection"
+		return host
+	}
+
+	if err = s.changePassword(conn, cred.Username, cred.Password, newPassword); err != nil {
+		s.Logger.Warn("Failed to change password on %s: %v", conn.Label, err)
+		host.result.Error = err.Error()
+		return host
+	}
+	// the command may have succeeded partially, so roll back from here on
+	host.changed = true
+
+	if err = s.login(conn, cred.Username, newPassword); err != nil {
+		s.Logger.Warn("New password was not accepted by %s: %v", conn.Label, err)
+		host.result.Error = fmt.Sprintf("verification failed: %v", err)
+		return host
+	}
+	host.result.Status = enums.RotationStatusVerified
+	return host
+}
+
+// rollback restores the old password on every host that was changed. Hosts where
+// the new password never worked are tried with the old password first, in case the
+// change did not apply.
+func (s *RotationSrv) rollback(cred *model.Credential, hosts []*rotationHost, newPassword string) {
+	for _, host := range hosts {
+		if host.result.Status != enums.RotationStatusVerified && s.login(host.conn, cred.Username, cred.Password) == nil {
+			host.result.Status = enums.RotationStatusFailed
+			continue
+		}
+		if err := s.changePassword(host.conn, cred.Username, newPassword, cred.Password); err != nil {
+			host.result.Status = enums.RotationStatusRollbackFailed
+			host.result.RollbackError = err.Error()
+			s.Logger.Error("Failed to roll back password on %s: %v", host.conn.Label, err)
+			continue
+		}
+		if err := s.login(host.conn, cred.Username, cred.Password); err != nil {
+			host.result.Status = enums.RotationStatusRollbackFailed
+			host.result.RollbackError = fmt.Sprintf("verification failed: %v", err)
+			s.Logger.Error("Old password was not accepted by %s after rollback: %v", host.conn.Label, err)
+			continue
+		}
+		host.result.Status = enums.RotationStatusRolledBack
+	}
+}
+
+func (s *RotationSrv) changePassword(conn *model.Connection, user, oldPassword, newPassword string) error {
+	vendor := ""
+	if conn.Metadata != nil {
+		vendor = conn.Metadata.Vendor
+	}
+	change, err := rotate.ChangeFor(vendor, user, oldPassword, newPassword)
+	if err != nil {
+		return err
+	}
+	conf, err := s.passwordConfig(conn, user, oldPassword)
+	if err != nil {
+		return err
+	}
+	client, err := exec.NewExec(conf, s.Logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+	return change.Run(exec.New(client))
+}
+
+// login verifies a password with a fresh connection.
+func (s *RotationSrv) login(conn *model.Connection, user, password string) error {
+	conf, err := s.passwordConfig(conn, user, password)
+	if err != nil {
+		return err
+	}
+	client, err := commonssh.NewSSHClient(conf, s.Logger)
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
+// passwordConfig keeps the host, algorithms and host key handling of the connection
+// but authenticates with exactly one password.
+func (s *RotationSrv) passwordConfig(conn *model.Connection, user, password string) (*commonssh.Config, error) {
+	conf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	if user == "" {
+		return nil, errors.New("credential has no username")
+	}
+	conf.User = user
+	conf.AuthMethod = enums.Password
+	conf.Password = password
+	conf.Identities = []*commonssh.Identity{{
+		Key:        "rotation",
+		User:       user,
+		AuthMethod: enums.Password,
+		Password:   password,
+	}}
+	conf.UseDefaultIdentities = false
+	conf.PreferredIdentity = ""
+	conf.OnAuthenticated = nil
+	return conf, nil
+}
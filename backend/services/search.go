@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/search"
+	"github.com/Q191/GTerm/backend/utils/resp"
+)
+
+// minMatchLength is the shortest free text the trigram index can MATCH.
+const minMatchLength = 3
+
+// searchColumns maps the search fields to columns of the full-text table.
+var searchColumns = map[string]string{
+	search.FieldLabel:    "label",
+	search.FieldHost:     "host",
+	search.FieldVendor:   "vendor",
+	search.FieldType:     "type",
+	search.FieldProtocol: "protocol",
+}
+
+// SearchConnection returns the connections matching a query such as
+// "tag:prod vendor:cisco host:10.*", ordered like ListConnection.
+func (s *ConnectionSrv) SearchConnection(text string) *resp.Resp {
+	expr, err := search.Parse(text)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	connList, err := findConnections(s.Query, expr)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(connList)
+}
+
+// ListConnectionTags returns every tag in use, for completion.
+func (s *ConnectionSrv) ListConnectionTags() *resp.Resp {
+	tags := make([]string, 0)
+	if err := s.Query.Connection.UnderlyingDB().
+		Raw(`SELECT DISTINCT json_each.value FROM connections, json_each(connections.tags)
+			WHERE connections.deleted_at IS NULL ORDER BY json_each.value`).
+		Scan(&tags).Error; err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(tags)
+}
+
+// findConnections returns the resolved connections matching expr.
+func findConnections(q *query.Query, expr search.Query) ([]*model.Connection, error) {
+	ids, err := searchConnectionIDs(q, expr)
+	if err != nil {
+		return nil, err
+	}
+	t := q.Connection
+	connList, err := t.Preload(t.Metadata, t.Credential).Where(t.ID.In(ids...)).Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return nil, err
+	}
+	if err = resolveConnections(q, connList); err != nil {
+		return nil, err
+	}
+	return connList, nil
+}
+
+func searchConnectionIDs(q *query.Query, expr search.Query) ([]uint, error) {
+	where, args := searchCondition(expr)
+	ids := make([]uint, 0)
+	err := q.Connection.UnderlyingDB().
+		Raw(fmt.Sprintf("SELECT rowid FROM %s WHERE %s", initialize.ConnectionSearchTable, where), args...).
+		Scan(&ids).Error
+	return ids, err
+}
+
+func searchCondition(expr search.Query) (string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	for _, clause := range expr {
+		terms := make([]string, 0, len(clause))
+		for _, term := range clause {
+			cond, termArgs := termCondition(term)
+			if term.Negate {
+				cond = "NOT IFNULL(" + cond + ", 0)"
+			}
+			terms = append(terms, cond)
+			args = append(args, termArgs...)
+		}
+		clauses = append(clauses, "("+strings.Join(terms, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args
+}
+
+// termCondition matches field values as a whole, with wildcards, and free text
+// anywhere. Matching is case-insensitive.
+func termCondition(term search.Term) (string, []any) {
+	switch {
+	case term.Field == search.FieldTag:
+		return `EXISTS (SELECT 1 FROM json_each(tags) WHERE json_each.value LIKE ? ESCAPE '\')`,
+			[]any{likePattern(term.Value, false)}
+	case term.Attribute():
+		return `EXISTS (SELECT 1 FROM json_each(attributes) WHERE json_each.key = ? COLLATE NOCASE
+				AND json_each.value LIKE ? ESCAPE '\')`,
+			[]any{term.Field, likePattern(term.Value, false)}
+	case term.Field != "":
+		return searchColumns[term.Field] + ` LIKE ? ESCAPE '\'`, []any{likePattern(term.Value, false)}
+	case !term.Wildcard() && len([]rune(term.Value)) >= minMatchLength:
+		return fmt.Sprintf("rowid IN (SELECT rowid FROM %s WHERE %[1]s MATCH ?)", initialize.ConnectionSearchTable),
+			[]any{`"` + strings.ReplaceAll(term.Value, `"`, `""`) + `"`}
+	}
+	pattern := likePattern(term.Value, true)
+	columns := append(slices.Sorted(maps.Values(searchColumns)), "tags", "attributes")
+	conds := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		conds[i] = column + ` LIKE ? ESCAPE '\'`
+		args[i] = pattern
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+// likePattern turns the search wildcards into LIKE ones, escaping everything else.
+func likePattern(value string, substring bool) string {
+	var b strings.Builder
+	if substring {
+		b.WriteByte('%')
+	}
+	for _, r := range value {
+		switch r {
+		case '\\', '%', '_':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if substring {
+		b.WriteByte('%')
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/secret"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+var SecretSrvSet = wire.NewSet(wire.Struct(new(SecretSrv), "*"))
+
+// SecretSrv resolves credentials whose secrets live in an external password
+// manager. Resolved values are only kept in memory.
+type SecretSrv struct {
+	Logger   initialize.Logger
+	resolver *secret.Resolver `wire:"-"`
+	once     sync.Once        `wire:"-"`
+}
+
+// Resolve fills in the secrets of a command sourced credential.
+func (s *SecretSrv) Resolve(cred *model.Credential) error {
+	if cred == nil || cred.Source != enums.CredentialSourceCommand {
+		return nil
+	}
+	ttl := time.Duration(cred.SecretCacheTTL) * time.Second
+	if cred.PasswordCommand != "" {
+		output, err := s.getResolver().Resolve(cred.PasswordCommand, ttl)
+		if err != nil {
+			return err
+		}
+		cred.Password = secret.FirstLine(output)
+	}
+	if cred.PrivateKeyCommand != "" {
+		output, err := s.getResolver().Resolve(cred.PrivateKeyCommand, ttl)
+		if err != nil {
+			return err
+		}
+		cred.PrivateKey = secret.PrivateKey(output)
+	}
+	if cred.PassphraseCommand != "" {
+		output, err := s.getResolver().Resolve(cred.PassphraseCommand, ttl)
+		if err != nil {
+			return err
+		}
+		cred.Passphrase = secret.FirstLine(output)
+	}
+	s.Logger.Debug("Resolved external secrets of credential %d", cred.ID)
+	return nil
+}
+
+// TestSecretCommand runs a command once so that it can be checked before saving.
+// Only the length of the output is returned.
+func (s *SecretSrv) TestSecretCommand(command string) *resp.Resp {
+	output, err := secret.Run(command)
+	if err != nil {
+		s.Logger.Warn("Secret command test failed: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCodeAndData(messages.SecretCommandSuccess, len(secret.FirstLine(output)))
+}
+
+func (s *SecretSrv) ClearSecretCache() *resp.Resp {
+	s.getResolver().Clear()
+	return resp.OkWithCode(messages.SecretCacheCleared)
+}
+
+func (s *SecretSrv) getResolver() *secret.Resolver {
+	s.once.Do(func() {
+		s.resolver = secret.NewResolver(s.Logger)
+	})
+	return s.resolver
+}
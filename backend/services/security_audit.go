@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+var SecurityAuditSrvSet = wire.NewSet(wire.Struct(new(SecurityAuditSrv), "*"))
+
+type SecurityAuditSrv struct {
+	Logger        initialize.Logger
+	Query         *query.Query
+	ConnectionSrv *ConnectionSrv
+	AppContext    *initialize.AppContext
+}
+
+type securityAuditExport struct {
+	ConnectionID uint                 `json:"connectionId"`
+	Label        string               `json:"label"`
+	Host         string               `json:"host"`
+	Port         uint                 `json:"port"`
+	Audit        *model.SecurityAudit `json:"audit"`
+}
+
+func (s *SecurityAuditSrv) AuditConnection(connID uint) *resp.Resp {
+	audit, err := s.audit(connID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCodeAndData(messages.AuditSuccess, audit)
+}
+
+func (s *SecurityAuditSrv) AuditGroup(groupID uint) *resp.Resp {
+	members, err := groupMembers(s.Query, groupID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	t := s.Query.Connection
+	connList, err := t.Where(members, t.ConnProtocol.Eq(string(enums.SSH))).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	failures := make(map[string]string)
+	for _, conn := range connList {
+		if _, err = s.audit(conn.ID); err != nil {
+			failures[conn.Label] = err.Error()
+		}
+	}
+	s.Logger.Info("Audited %d connections in group %d, %d failed", len(connList), groupID, len(failures))
+	return resp.OkWithCodeAndData(messages.AuditSuccess, failures)
+}
+
+func (s *SecurityAuditSrv) FindAuditByConnection(connID uint) *resp.Resp {
+	t := s.Query.SecurityAudit
+	audit, err := t.Where(t.ConnectionID.Eq(connID)).First()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(audit)
+}
+
+func (s *SecurityAuditSrv) ListGroupAudits(groupID uint) *resp.Resp {
+	exports, err := s.groupAudits(groupID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(exports)
+}
+
+func (s *SecurityAuditSrv) ExportGroupAudits(groupID uint, title string) *resp.Resp {
+	exports, err := s.groupAudits(groupID)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+
+	path, err := runtime.SaveFileDialog(s.AppContext.Context(), runtime.SaveDialogOptions{
+		Title:           title,
+		DefaultFilename: fmt.Sprintf("security-audit-group-%d.json", groupID),
+	})
+	if err != nil {
+		s.Logger.Error("Failed to open save dialog: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	if path == "" {
+		return resp.OkWithCode(messages.AuditUserCanceled)
+	}
+
+	data, err := json.MarshalIndent(exports, "", "  ")
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		s.Logger.Error("Failed to write security audit export: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Exported %d security audits to %s", len(exports), path)
+	return resp.OkWithCode(messages.AuditExportSuccess)
+}
+
+func (s *SecurityAuditSrv) audit(connID uint) (*model.SecurityAudit, error) {
+	_, conf, err := s.ConnectionSrv.transportConfig(connID)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := commonssh.ProbeServer(conf, s.Logger)
+	if err != nil {
+		s.Logger.Error("Security audit failed: %v, connID: %d", err, connID)
+		return nil, err
+	}
+	findings, rating := commonssh.AuditProbe(probe)
+
+	t := s.Query.SecurityAudit
+	audit, err := t.Where(t.ConnectionID.Eq(connID)).FirstOrInit()
+	if err != nil {
+		return nil, err
+	}
+	audit.ConnectionID = connID
+	audit.ServerVersion = probe.ServerVersion
+	audit.Offered = probe.Offered
+	audit.Expected = probe.Expected
+	audit.Findings = findings
+	audit.Rating = rating
+	if err = t.Save(audit); err != nil {
+		return nil, err
+	}
+	s.Logger.Info("Security audit completed, connID: %d, server: %s, rating: %s", connID, probe.ServerVersion, rating)
+	return audit, nil
+}
+
+func (s *SecurityAuditSrv) groupAudits(groupID uint) ([]*securityAuditExport, error) {
+	members, err := groupMembers(s.Query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	t := s.Query.Connection
+	connList, err := t.Where(members).Preload(t.SecurityAudit).Find()
+	if err != nil {
+		return nil, err
+	}
+	// the port may be inherited from a group
+	if err = resolveConnections(s.Query, connList); err != nil {
+		return nil, err
+	}
+	exports := make([]*securityAuditExport, 0, len(connList))
+	for _, conn := range connList {
+		if conn.SecurityAudit == nil {
+			continue
+		}
+		exports = append(exports, &securityAuditExport{
+			ConnectionID: conn.ID,
+			Label:        conn.Label,
+			Host:         conn.Host,
+			Port:         conn.Port,
+			Audit:        conn.SecurityAudit,
+		})
+	}
+	return exports, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/terminal"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+var SessionSrvSet = wire.NewSet(wire.Struct(new(SessionSrv), "*"))
+
+// SessionSrv is the registry of live terminal sessions and their statistics.
+type SessionSrv struct {
+	Logger   initialize.Logger
+	sessions map[string]*terminal.Stats `wire:"-"`
+	mutex    sync.RWMutex               `wire:"-"`
+}
+
+func (s *SessionSrv) Register(stats *terminal.Stats) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*terminal.Stats)
+	}
+	s.sessions[stats.ID()] = stats
+	s.Logger.Debug("Session registered: %s", stats.ID())
+}
+
+func (s *SessionSrv) Unregister(stats *terminal.Stats) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, stats.ID())
+	s.Logger.Debug("Session unregistered: %s", stats.ID())
+}
+
+func (s *SessionSrv) ListSessions() *resp.Resp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	sessions := make([]*types.SessionStats, 0, len(s.sessions))
+	for _, stats := range s.sessions {
+		sessions = append(sessions, stats.Snapshot())
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+	return resp.OkWithData(sessions)
+}
+
+func (s *SessionSrv) FindSessionByID(id string) *resp.Resp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	stats, ok := s.sessions[id]
+	if !ok {
+		return resp.FailWithMsg("session not found")
+	}
+	return resp.OkWithData(stats.Snapshot())
+}
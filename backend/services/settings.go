@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/types"
+)
+
+const (
+	// maxGroupDepth guards against parent loops that bypassed the cycle check.
+	maxGroupDepth = 64
+
+	credentialSetting = "credentialID"
+)
+
+// defaultConnectionSettings apply when neither the connection nor any of its groups
+// set a value.
+var defaultConnectionSettings = model.ConnectionSettings{
+	Port:       22,
+	Theme:      "Default",
+	SSHCharset: "UTF-8",
+}
+
+// groupChain returns the group and its ancestors, nearest first.
+func groupChain(q *query.Query, groupID *uint) ([]*model.Group, error) {
+	if groupID == nil {
+		return nil, nil
+	}
+	groups, err := groupsByID(q)
+	if err != nil {
+		return nil, err
+	}
+	return groupChainFrom(groups, groupID)
+}
+
+func groupChainFrom(groups map[uint]*model.Group, groupID *uint) ([]*model.Group, error) {
+	var chain []*model.Group
+	for id := groupID; id != nil; {
+		if len(chain) >= maxGroupDepth {
+			return nil, errors.New("group nesting is too deep")
+		}
+		group, ok := groups[*id]
+		if !ok {
+			return nil, fmt.Errorf("group %d not found", *id)
+		}
+		chain = append(chain, group)
+		id = group.ParentID
+	}
+	return chain, nil
+}
+
+func groupsByID(q *query.Query) (map[uint]*model.Group, error) {
+	groups, err := q.Group.Find()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Group, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+	return byID, nil
+}
+
+// resolveConnections resolves the settings of every connection in the list, loading
+// the groups only once.
+func resolveConnections(q *query.Query, connList []*model.Connection) error {
+	groups, err := groupsByID(q)
+	if err != nil {
+		return err
+	}
+	for _, conn := range connList {
+		chain, err := groupChainFrom(groups, conn.GroupID)
+		if err != nil {
+			return err
+		}
+		resolveSettings(conn, chain)
+	}
+	return nil
+}
+
+// resolveSettings fills every setting the connection does not override from the
+// nearest group setting it, or the default, and records where each value came from.
+// The credential is only inherited by connections using common credentials. The
+// overrides are recorded explicitly in Overrides afterwards.
+func resolveSettings(conn *model.Connection, chain []*model.Group) {
+	conn.SettingOrigins = make(map[string]*types.SettingOrigin)
+	overrides := make([]string, 0)
+
+	target := reflect.ValueOf(&conn.ConnectionSettings).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		name := settingName(target.Type().Field(i))
+		field := target.Field(i)
+		if overridden(conn, name, field) {
+			conn.SettingOrigins[name] = &types.SettingOrigin{Source: enums.SettingSourceConnection}
+			overrides = append(overrides, name)
+			continue
+		}
+		field.Set(reflect.Zero(field.Type()))
+		inherited := false
+		for _, group := range chain {
+			if value := reflect.ValueOf(group.ConnectionSettings).Field(i); !value.IsZero() {
+				field.Set(value)
+				conn.SettingOrigins[name] = groupOrigin(group)
+				inherited = true
+				break
+			}
+		}
+		if value := reflect.ValueOf(defaultConnectionSettings).Field(i); !inherited && !value.IsZero() {
+			field.Set(value)
+			conn.SettingOrigins[name] = &types.SettingOrigin{Source: enums.SettingSourceDefault}
+		}
+	}
+
+	switch {
+	case !conn.UseCommonCredential:
+		conn.SettingOrigins[credentialSetting] = &types.SettingOrigin{Source: enums.SettingSourceConnection}
+	case conn.CredentialID != nil && overridden(conn, credentialSetting, reflect.ValueOf(conn.CredentialID)):
+		conn.SettingOrigins[credentialSetting] = &types.SettingOrigin{Source: enums.SettingSourceConnection}
+		overrides = append(overrides, credentialSetting)
+	default:
+		conn.CredentialID, conn.Credential = nil, nil
+		for _, group := range chain {
+			if group.CredentialID != nil {
+				id := *group.CredentialID
+				conn.CredentialID = &id
+				conn.SettingOrigins[credentialSetting] = groupOrigin(group)
+				break
+			}
+		}
+	}
+	conn.Overrides = overrides
+}
+
+// applyOverrides empties the settings missing from Overrides before the connection
+// is saved, so they keep following the groups, and keeps the listed ones even when
+// they equal the inherited value. Without Overrides, as for connections stamped
+// from templates, every non-empty setting counts as overridden.
+func applyOverrides(conn *model.Connection) {
+	overrides := make([]string, 0)
+	target := reflect.ValueOf(&conn.ConnectionSettings).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		name := settingName(target.Type().Field(i))
+		if overridden(conn, name, target.Field(i)) {
+			overrides = append(overrides, name)
+			continue
+		}
+		target.Field(i).Set(reflect.Zero(target.Field(i).Type()))
+	}
+
+	if conn.UseCommonCredential {
+		if conn.CredentialID != nil && overridden(conn, credentialSetting, reflect.ValueOf(conn.CredentialID)) {
+			overrides = append(overrides, credentialSetting)
+		} else {
+			conn.CredentialID, conn.Credential = nil, nil
+		}
+	}
+	conn.Overrides = overrides
+	conn.SettingOrigins = nil
+}
+
+// overridden reports whether the connection sets the setting itself.
+func overridden(conn *model.Connection, name string, value reflect.Value) bool {
+	if conn.Overrides == nil {
+		return !value.IsZero()
+	}
+	return slices.Contains(conn.Overrides, name)
+}
+
+func groupOrigin(group *model.Group) *types.SettingOrigin {
+	return &types.SettingOrigin{Source: enums.SettingSourceGroup, GroupID: group.ID, GroupName: group.Name}
+}
+
+func settingName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/pkg/hostrange"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+)
+
+const defaultLabelPattern = "{host}"
+
+var TemplateSrvSet = wire.NewSet(wire.Struct(new(TemplateSrv), "*"))
+
+// labelPlaceholder matches {host}, {n} and the bracket values {1} to {9}.
+var labelPlaceholder = regexp.MustCompile(`\{(host|n|[1-9])\}`)
+
+type TemplateSrv struct {
+	Logger      initialize.Logger
+	Query       *query.Query
+	AuditLogSrv *AuditLogSrv
+}
+
+func (s *TemplateSrv) CreateTemplate(tmpl *model.ConnectionTemplate) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	if err := validateTemplate(tmpl); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		if err := checkStaticGroup(tx, tmpl.GroupID); err != nil {
+			return err
+		}
+		if !tmpl.UseCommonCredential && tmpl.Credential != nil {
+			if err := createPrivateCredential(tx, tmpl.Credential); err != nil {
+				return err
+			}
+			tmpl.CredentialID = &tmpl.Credential.ID
+		}
+		return tx.ConnectionTemplate.Create(tmpl)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if !tmpl.UseCommonCredential && tmpl.Credential != nil {
+		s.AuditLogSrv.recordTemplateCredential(enums.AuditActionCredentialCreate, tmpl, tmpl.Credential.ID)
+	}
+	return resp.OkWithCode(messages.CreateSuccess)
+}
+
+func (s *TemplateSrv) UpdateTemplate(tmpl *model.ConnectionTemplate) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	if err := validateTemplate(tmpl); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	var credAction enums.AuditAction
+	var credID uint
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		t := tx.ConnectionTemplate
+		old, err := t.Where(t.ID.Eq(tmpl.ID)).First()
+		if err != nil {
+			return err
+		}
+		if err = checkStaticGroup(tx, tmpl.GroupID); err != nil {
+			return err
+		}
+		private := !old.UseCommonCredential && old.CredentialID != nil
+		switch {
+		case !tmpl.UseCommonCredential && tmpl.Credential != nil && private:
+			tmpl.Credential.ID = *old.CredentialID
+			tmpl.Credential.IsCommonCredential = false
+			if err = tx.Credential.Where(tx.Credential.ID.Eq(*old.CredentialID)).Save(tmpl.Credential); err != nil {
+				return err
+			}
+			tmpl.CredentialID = old.CredentialID
+			credAction, credID = enums.AuditActionCredentialUpdate, *old.CredentialID
+		case !tmpl.UseCommonCredential && tmpl.Credential != nil:
+			if err = createPrivateCredential(tx, tmpl.Credential); err != nil {
+				return err
+			}
+			tmpl.CredentialID = &tmpl.Credential.ID
+			credAction, credID = enums.AuditActionCredentialCreate, tmpl.Credential.ID
+		case tmpl.UseCommonCredential && private:
+			if err = trashPrivateCredential(tx, *old.CredentialID, old.Name); err != nil {
+				return err
+			}
+			credAction, credID = enums.AuditActionCredentialDelete, *old.CredentialID
+		}
+		return t.Where(t.ID.Eq(tmpl.ID)).Save(tmpl)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if credAction != "" {
+		s.AuditLogSrv.recordTemplateCredential(credAction, tmpl, credID)
+	}
+	return resp.OkWithCode(messages.UpdateSuccess)
+}
+
+func (s *TemplateSrv) DeleteTemplate(id uint) *resp.Resp {
+	var tmpl *model.ConnectionTemplate
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		t := tx.ConnectionTemplate
+		var err error
+		if tmpl, err = t.Where(t.ID.Eq(id)).First(); err != nil {
+			return err
+		}
+		if !tmpl.UseCommonCredential && tmpl.CredentialID != nil {
+			if err = trashPrivateCredential(tx, *tmpl.CredentialID, tmpl.Name); err != nil {
+				return err
+			}
+		}
+		_, err = t.Where(t.ID.Eq(id)).Unscoped().Delete()
+		return err
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if !tmpl.UseCommonCredential && tmpl.CredentialID != nil {
+		s.AuditLogSrv.recordTemplateCredential(enums.AuditActionCredentialDelete, tmpl, *tmpl.CredentialID)
+	}
+	return resp.OkWithCode(messages.DeleteSuccess)
+}
+
+func (s *TemplateSrv) ListTemplate() *resp.Resp {
+	t := s.Query.ConnectionTemplate
+	templates, err := t.Order(t.Name).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(templates)
+}
+
+func (s *TemplateSrv) FindTemplateByID(id uint) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	t := s.Query.ConnectionTemplate
+	tmpl, err := t.Where(t.ID.Eq(id)).Preload(t.Credential).First()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if tmpl.Credential != nil {
+		if err = tmpl.Credential.Decrypt(); err != nil {
+			return resp.FailWithMsg(err.Error())
+		}
+		s.AuditLogSrv.recordTemplateCredential(enums.AuditActionCredentialDecrypt, tmpl, tmpl.Credential.ID)
+	}
+	return resp.OkWithData(tmpl)
+}
+
+// PreviewTemplate lists the connections StampTemplate would create, marking labels
+// that are already taken.
+func (s *TemplateSrv) PreviewTemplate(req *types.StampRequest) *resp.Resp {
+	_, stamped, err := s.plan(s.Query, req)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(stamped)
+}
+
+// StampTemplate creates one connection per host matched by req.Hosts, all or none.
+// The label pattern may use {host}, {n} for the position starting at 1, and {1} to
+// {9} for the value of each bracket in the host pattern, so "sw-{1}" with hosts
+// 10.0.1.[1-40] gives sw-1 to sw-40.
+func (s *TemplateSrv) StampTemplate(req *types.StampRequest) *resp.Resp {
+	if r := requireUnlocked(); r != nil {
+		return r
+	}
+	var stamped []*types.StampedConnection
+	var created []*model.Connection
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		tmpl, planned, err := s.plan(tx, req)
+		if err != nil {
+			return err
+		}
+		var taken []string
+		for _, item := range planned {
+			if item.Exists {
+				taken = append(taken, item.Label)
+			}
+		}
+		if len(taken) > 0 {
+			return fmt.Errorf("labels already in use: %s", strings.Join(taken, ", "))
+		}
+		for _, item := range planned {
+			conn, err := stampConnection(tmpl, item)
+			if err != nil {
+				return err
+			}
+			if req.GroupID != nil {
+				conn.GroupID = req.GroupID
+			}
+			if err = createConnection(tx, conn); err != nil {
+				return fmt.Errorf("%s: %w", item.Label, err)
+			}
+			item.ID = conn.ID
+			created = append(created, conn)
+		}
+		stamped = planned
+		return nil
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	for _, conn := range created {
+		s.AuditLogSrv.recordCreatedCredential(conn)
+	}
+	s.Logger.Info("Stamped %d connections from template %d", len(stamped), req.TemplateID)
+	return resp.OkWithCodeAndData(messages.CreateSuccess, stamped)
+}
+
+// plan expands the hosts of the request and names each connection.
+func (s *TemplateSrv) plan(tx *query.Query, req *types.StampRequest) (*model.ConnectionTemplate, []*types.StampedConnection, error) {
+	t := tx.ConnectionTemplate
+	tmpl, err := t.Where(t.ID.Eq(req.TemplateID)).Preload(t.Credential).First()
+	if err != nil {
+		return nil, nil, err
+	}
+	pattern := req.LabelPattern
+	if pattern == "" {
+		pattern = tmpl.LabelPattern
+	}
+	if pattern == "" {
+		pattern = defaultLabelPattern
+	}
+	hosts, err := hostrange.ExpandAll(req.Hosts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stamped := make([]*types.StampedConnection, 0, len(hosts))
+	labels := make([]string, 0, len(hosts))
+	for i, host := range hosts {
+		label, err := stampLabel(pattern, host, i+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if slices.Contains(labels, label) {
+			return nil, nil, fmt.Errorf("label pattern %q gives %q more than once", pattern, label)
+		}
+		labels = append(labels, label)
+		stamped = append(stamped, &types.StampedConnection{Label: label, Host: host.Name})
+	}
+
+	c := tx.Connection
+	existing, err := c.Where(c.Label.In(labels...)).Find()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, conn := range existing {
+		if i := slices.Index(labels, conn.Label); i >= 0 {
+			stamped[i].Exists = true
+		}
+	}
+	return tmpl, stamped, nil
+}
+
+func stampLabel(pattern string, host *hostrange.Host, n int) (string, error) {
+	var err error
+	label := labelPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		switch name := placeholder[1 : len(placeholder)-1]; name {
+		case "host":
+			return host.Name
+		case "n":
+			return strconv.Itoa(n)
+		default:
+			part, _ := strconv.Atoi(name)
+			if part > len(host.Parts) {
+				err = fmt.Errorf("label pattern uses %s but host %s has only %d ranges", placeholder, host.Name, len(host.Parts))
+				return placeholder
+			}
+			return host.Parts[part-1]
+		}
+	})
+	return label, err
+}
+
+func stampConnection(tmpl *model.ConnectionTemplate, item *types.StampedConnection) (*model.Connection, error) {
+	conn := &model.Connection{
+		Label:                 item.Label,
+		Host:                  item.Host,
+		ConnProtocol:          tmpl.ConnProtocol,
+		UseCommonCredential:   tmpl.UseCommonCredential,
+		GroupID:               tmpl.GroupID,
+		FallbackCredentialIDs: slices.Clone(tmpl.FallbackCredentialIDs),
+		TryDefaultIdentities:  tmpl.TryDefaultIdentities,
+		MaxAuthTries:          tmpl.MaxAuthTries,
+		DebugTrace:            tmpl.DebugTrace,
+		Tags:                  slices.Clone(tmpl.Tags),
+		Attributes:            maps.Clone(tmpl.Attributes),
+		ConnectionSettings:    tmpl.ConnectionSettings,
+	}
+	if tmpl.UseCommonCredential {
+		conn.CredentialID = tmpl.CredentialID
+		return conn, nil
+	}
+	if tmpl.Credential != nil {
+		var err error
+		if conn.Credential, err = copyCredential(tmpl.Credential); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func validateTemplate(tmpl *model.ConnectionTemplate) error {
+	if strings.TrimSpace(tmpl.Name) == "" {
+		return errors.New("template name is required")
+	}
+	if err := validateSettings(&tmpl.ConnectionSettings); err != nil {
+		return err
+	}
+	var err error
+	tmpl.Tags, tmpl.Attributes, err = normalizeTags(tmpl.Tags, tmpl.Attributes)
+	return err
+}
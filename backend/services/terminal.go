@@ -9,6 +9,7 @@ import (
 
 	"github.com/Q191/GTerm/backend/consts"
 	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
 	"github.com/Q191/GTerm/backend/enums"
 	"github.com/Q191/GTerm/backend/initialize"
 	"github.com/Q191/GTerm/backend/pkg/terminal"
@@ -26,6 +27,10 @@ type TerminalSrv struct {
 	Logger           initialize.Logger
 	ConnectionSrv    *ConnectionSrv
 	MetadataSrv      *MetadataSrv
+	TraceSrv         *TraceSrv
+	SessionSrv       *SessionSrv
+	AuditLogSrv      *AuditLogSrv
+	HistorySrv       *HistorySrv
 	HTTPListenerPort *initialize.HTTPListenerPort
 }
 
@@ -43,53 +48,29 @@ func (s *TerminalSrv) SSH(ws *websocket.Conn, hostID uint) error {
 		go s.MetadataSrv.UpdateByConnection(conn)
 	}
 
-	sshConf := &commonssh.Config{
-		Host:       conn.Host,
-		Port:       conn.Port,
-		User:       conn.Credential.Username,
-		AuthMethod: conn.Credential.AuthMethod,
-		Password:   conn.Credential.Password,
-		PrivateKey: conn.Credential.PrivateKey,
-		Passphrase: conn.Credential.Passphrase,
+	sshConf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		s.Logger.Error("Failed to build SSH configuration: %v, hostID: %d", err, hostID)
+		return err
 	}
 
-	// if len(conn.SSHCiphers) > 0 {
-	// 	sshConf.Ciphers = conn.SSHCiphers
-	// 	s.Logger.Debug("Using custom ciphers: %v", conn.SSHCiphers)
-	// }
-	//
-	// if len(conn.SSHKeyExchanges) > 0 {
-	// 	sshConf.KeyExchanges = conn.SSHKeyExchanges
-	// 	s.Logger.Debug("Using custom key exchanges: %v", conn.SSHKeyExchanges)
-	// }
-	//
-	// if len(conn.SSHMACs) > 0 {
-	// 	sshConf.MACs = conn.SSHMACs
-	// 	s.Logger.Debug("Using custom MACs: %v", conn.SSHMACs)
-	// }
-	//
-	// if len(conn.SSHPublicKeyAlgorithms) > 0 {
-	// 	sshConf.PublicKeyAlgorithms = conn.SSHPublicKeyAlgorithms
-	// 	s.Logger.Debug("Using custom public key algorithms: %v", conn.SSHPublicKeyAlgorithms)
-	// }
-	//
-	// if len(conn.SSHHostKeyAlgorithms) > 0 {
-	// 	sshConf.HostKeyAlgorithms = conn.SSHHostKeyAlgorithms
-	// 	s.Logger.Debug("Using custom host key algorithms: %v", conn.SSHHostKeyAlgorithms)
-	// }
-	//
 	// if conn.SSHCharset != "" {
 	// 	sshConf.Charset = conn.SSHCharset
 	// 	s.Logger.Debug("Using charset: %s", conn.SSHCharset)
 	// }
 
-	s.Logger.Info("SSH configuration ready, host: %s, user: %s, auth method: %s",
+	s.Logger.Info("SSH configuration ready, host: %s, user: %s, auth method: %s, identities: %d",
 		conn.Host,
-		conn.Credential.Username,
-		conn.Credential.AuthMethod)
+		sshConf.User,
+		sshConf.AuthMethod,
+		len(sshConf.Identities))
+
+	sshConf.Trace = s.TraceSrv.Start(conn)
 
 	s.Logger.Info("Connecting to SSH server, host: %s, port: %d", conn.Host, conn.Port)
-	ssh, err := adapter.NewSSH(sshConf, ws, s.Logger).Connect()
+	stats := terminal.NewStats(conn.ID, fmt.Sprintf("%s:%d", conn.Host, conn.Port))
+	ssh, err := adapter.NewSSH(sshConf, ws, stats, s.Logger).Connect()
+	sshConf.Trace.Finish(err)
 	if err != nil {
 		s.Logger.Error("SSH connection failed: %v, host: %s, port: %d", err, conn.Host, conn.Port)
 		return err
@@ -103,6 +84,22 @@ func (s *TerminalSrv) SSH(ws *websocket.Conn, hostID uint) error {
 	}
 	s.Logger.Info("Connection success message sent")
 
+	s.SessionSrv.Register(stats)
+	defer s.SessionSrv.Unregister(stats)
+	s.HistorySrv.connected(ws)
+
+	openedAt := time.Now()
+	target := fmt.Sprintf("%s@%s:%d", sshConf.User, conn.Host, conn.Port)
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionSessionOpen, ConnectionID: conn.ID, Target: target})
+	defer func() {
+		s.AuditLogSrv.record(&model.AuditLog{
+			Action:       enums.AuditActionSessionClose,
+			ConnectionID: conn.ID,
+			Target:       target,
+			Detail:       fmt.Sprintf("duration %s", time.Since(openedAt).Round(time.Second)),
+		})
+	}()
+
 	term := terminal.NewTerminal(ws, ssh, s.SessionEnded, s.Logger)
 	s.Logger.Info("Starting terminal session, host: %s, port: %d", conn.Host, conn.Port)
 	term.Start()
@@ -117,16 +114,43 @@ func (s *TerminalSrv) AddFingerprint(hostID uint, host string, fingerprint strin
 		s.Logger.Error("Failed to find host information: %v, hostID: %d", err, hostID)
 		return fmt.Errorf("failed to find host: %v", err)
 	}
-	sshConf := &commonssh.Config{
-		Host: conn.Host,
-		Port: conn.Port,
-		User: conn.Credential.Username,
+	sshConf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		s.Logger.Error("Failed to build SSH configuration: %v, hostID: %d", err, hostID)
+		return err
 	}
 	if err = commonssh.AddFingerprint(sshConf, host, fingerprint, s.Logger); err != nil {
 		s.Logger.Error("Failed to add host fingerprint: %v, host: %s", err, host)
 		return fmt.Errorf("failed to add host fingerprint: %v", err)
 	}
 	s.Logger.Info("Successfully added host fingerprint, host: %s", host)
+	s.AuditLogSrv.record(&model.AuditLog{Action: enums.AuditActionHostKeyAccept, ConnectionID: hostID, Target: host, Detail: fingerprint})
+	return nil
+}
+
+func (s *TerminalSrv) ReplaceFingerprint(hostID uint, host string, fingerprint string) error {
+	s.Logger.Info("Replacing host fingerprint, hostID: %d, host: %s, fingerprint: %s", hostID, host, fingerprint)
+	conn, err := s.ConnectionSrv.FindByID(hostID)
+	if err != nil {
+		s.Logger.Error("Failed to find host information: %v, hostID: %d", err, hostID)
+		return fmt.Errorf("failed to find host: %v", err)
+	}
+	sshConf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		s.Logger.Error("Failed to build SSH configuration: %v, hostID: %d", err, hostID)
+		return err
+	}
+	if err = commonssh.ReplaceFingerprint(sshConf, host, fingerprint, s.Logger); err != nil {
+		s.Logger.Error("Failed to replace host fingerprint: %v, host: %s", err, host)
+		return fmt.Errorf("failed to replace host fingerprint: %v", err)
+	}
+	s.Logger.Info("Successfully replaced host fingerprint, host: %s", host)
+	s.AuditLogSrv.record(&model.AuditLog{
+		Action:       enums.AuditActionHostKeyAccept,
+		ConnectionID: hostID,
+		Target:       host,
+		Detail:       fmt.Sprintf("%s, replaced changed key", fingerprint),
+	})
 	return nil
 }
 
@@ -160,6 +184,7 @@ func (s *TerminalSrv) SerialPorts() *resp.Resp {
 
 func (s *TerminalSrv) CloseSession(ws *websocket.Conn, reason string) {
 	s.Logger.Info("Closing session, reason: %s", reason)
+	s.HistorySrv.finish(ws, reason)
 	data := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
 	err := ws.WriteControl(websocket.CloseMessage, data, time.Now().Add(consts.WebSocketWriteWait))
 	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
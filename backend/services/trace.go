@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/initialize"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxTraces is the number of connection attempts kept in memory.
+const maxTraces = 50
+
+var TraceSrvSet = wire.NewSet(wire.Struct(new(TraceSrv), "*"))
+
+type TraceSrv struct {
+	Logger     initialize.Logger
+	AppContext *initialize.AppContext
+	traces     []*commonssh.Trace `wire:"-"`
+	mutex      sync.Mutex         `wire:"-"`
+}
+
+// Start returns a new trace for conn when debug tracing is enabled on it, or nil.
+func (s *TraceSrv) Start(conn *model.Connection) *commonssh.Trace {
+	if !conn.DebugTrace {
+		return nil
+	}
+	trace := commonssh.NewTrace(conn.ID, fmt.Sprintf("%s:%d", conn.Host, conn.Port))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.traces = append(s.traces, trace)
+	if len(s.traces) > maxTraces {
+		s.traces = s.traces[len(s.traces)-maxTraces:]
+	}
+	return trace
+}
+
+func (s *TraceSrv) ListTraces(connID uint) *resp.Resp {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	traces := make([]*types.SSHTrace, 0)
+	for i := len(s.traces) - 1; i >= 0; i-- {
+		trace := s.traces[i].Snapshot()
+		if connID == 0 || trace.ConnectionID == connID {
+			traces = append(traces, trace)
+		}
+	}
+	return resp.OkWithData(traces)
+}
+
+func (s *TraceSrv) FindTraceByID(id string) *resp.Resp {
+	trace := s.find(id)
+	if trace == nil {
+		return resp.FailWithMsg("trace not found")
+	}
+	return resp.OkWithData(trace)
+}
+
+func (s *TraceSrv) ExportTrace(id string, title string) *resp.Resp {
+	trace := s.find(id)
+	if trace == nil {
+		return resp.FailWithMsg("trace not found")
+	}
+
+	path, err := runtime.SaveFileDialog(s.AppContext.Context(), runtime.SaveDialogOptions{
+		Title:           title,
+		DefaultFilename: fmt.Sprintf("ssh-trace-%s.log", trace.StartedAt.Format("20060102-150405")),
+	})
+	if err != nil {
+		s.Logger.Error("Failed to open save dialog: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	if path == "" {
+		return resp.OkWithCode(messages.TraceUserCanceled)
+	}
+
+	if err = os.WriteFile(path, []byte(formatTrace(trace)), 0644); err != nil {
+		s.Logger.Error("Failed to write trace: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.TraceExportSuccess)
+}
+
+func (s *TraceSrv) ClearTraces() *resp.Resp {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.traces = nil
+	return resp.OkWithCode(messages.DeleteSuccess)
+}
+
+func (s *TraceSrv) find(id string) *types.SSHTrace {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, trace := range s.traces {
+		if snapshot := trace.Snapshot(); snapshot.ID == id {
+			return snapshot
+		}
+	}
+	return nil
+}
+
+func formatTrace(trace *types.SSHTrace) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s, connection %d, started %s\n", trace.Host, trace.ConnectionID, trace.StartedAt.Format(time.RFC3339))
+	for _, event := range trace.Events {
+		fmt.Fprintf(&b, "[%6dms] %-8s %s\n", event.Elapsed, event.Stage, event.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+const restoredSuffix = "restored"
+
+var TrashSrvSet = wire.NewSet(wire.Struct(new(TrashSrv), "*"))
+
+// TrashSrv restores and purges deleted connections, credentials and groups.
+type TrashSrv struct {
+	Logger         initialize.Logger
+	Query          *query.Query
+	PreferencesSrv *PreferencesSrv
+}
+
+func (s *TrashSrv) ListTrash() *resp.Resp {
+	t := s.Query.TrashItem
+	items, err := t.Order(t.ID.Desc()).Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(items)
+}
+
+// RestoreTrashItem brings back everything deleted together. Names taken in the
+// meantime get a "(restored)" suffix, and items whose group is gone are put at the
+// top level; both are reported in the result.
+func (s *TrashSrv) RestoreTrashItem(id uint) *resp.Resp {
+	result := &types.TrashRestoreResult{
+		Renamed:   make([]*types.TrashRename, 0),
+		Ungrouped: make([]string, 0),
+	}
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		item, err := tx.TrashItem.Where(tx.TrashItem.ID.Eq(id)).First()
+		if err != nil {
+			return err
+		}
+		if err = restoreGroups(tx, item.GroupIDs, result); err != nil {
+			return err
+		}
+		if item.Kind == enums.TrashKindCredential {
+			// see trashPrivateCredential
+			t := tx.Credential
+			if _, err = t.Unscoped().Where(t.ID.In(item.CredentialIDs...), t.IsCommonCredential.Is(false)).
+				UpdateSimple(t.IsCommonCredential.Value(true), t.Label.Value(item.Name)); err != nil {
+				return err
+			}
+		}
+		if err = restoreCredentials(tx, item.CredentialIDs, result); err != nil {
+			return err
+		}
+		if err = restoreConnections(tx, item.ConnectionIDs, result); err != nil {
+			return err
+		}
+		if len(item.MetadataIDs) > 0 {
+			t := tx.Metadata
+			if _, err = t.Unscoped().Where(t.ID.In(item.MetadataIDs...)).UpdateSimple(t.DeletedAt.Value(gorm.DeletedAt{})); err != nil {
+				return err
+			}
+		}
+		if len(item.SecurityAuditIDs) > 0 {
+			t := tx.SecurityAudit
+			if _, err = t.Unscoped().Where(t.ID.In(item.SecurityAuditIDs...)).UpdateSimple(t.DeletedAt.Value(gorm.DeletedAt{})); err != nil {
+				return err
+			}
+		}
+		if len(item.TunnelIDs) > 0 {
+			t := tx.Tunnel
+			if _, err = t.Unscoped().Where(t.ID.In(item.TunnelIDs...)).UpdateSimple(t.DeletedAt.Value(gorm.DeletedAt{})); err != nil {
+				return err
+			}
+		}
+		_, err = tx.TrashItem.Where(tx.TrashItem.ID.Eq(id)).Delete()
+		return err
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Restored trash item %d, %d renamed", id, len(result.Renamed))
+	return resp.OkWithCodeAndData(messages.TrashRestoreSuccess, result)
+}
+
+func (s *TrashSrv) PurgeTrashItem(id uint) *resp.Resp {
+	if err := s.Query.Transaction(func(tx *query.Query) error {
+		item, err := tx.TrashItem.Where(tx.TrashItem.ID.Eq(id)).First()
+		if err != nil {
+			return err
+		}
+		return purgeTrashItem(tx, item)
+	}); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.DeleteSuccess)
+}
+
+func (s *TrashSrv) EmptyTrash() *resp.Resp {
+	count, err := s.purge(s.Query.TrashItem.ID.IsNotNull())
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Emptied trash, %d items purged", count)
+	return resp.OkWithCode(messages.TrashPurgeSuccess)
+}
+
+// Purge permanently deletes the items older than the retention period. It is
+// called once at startup.
+func (s *TrashSrv) Purge() {
+	prefs, err := s.PreferencesSrv.preferences()
+	if err != nil {
+		s.Logger.Error("Failed to load preferences: %v", err)
+		return
+	}
+	if prefs.TrashRetentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -prefs.TrashRetentionDays)
+	count, err := s.purge(s.Query.TrashItem.CreatedAt.Lt(cutoff))
+	if err != nil {
+		s.Logger.Error("Failed to purge trash: %v", err)
+		return
+	}
+	if count > 0 {
+		s.Logger.Info("Purged %d trash items older than %d days", count, prefs.TrashRetentionDays)
+	}
+}
+
+func (s *TrashSrv) purge(cond gen.Condition) (int, error) {
+	var count int
+	err := s.Query.Transaction(func(tx *query.Query) error {
+		items, err := tx.TrashItem.Where(cond).Find()
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err = purgeTrashItem(tx, item); err != nil {
+				return err
+			}
+		}
+		count = len(items)
+		return nil
+	})
+	return count, err
+}
+
+// trashConnection soft deletes the connection with its private credential,
+// metadata, security audit and tunnel profiles, and records them in item.
+func trashConnection(tx *query.Query, conn *model.Connection, item *model.TrashItem) error {
+	if !conn.UseCommonCredential && conn.CredentialID != nil {
+		if _, err := tx.Credential.Where(tx.Credential.ID.Eq(*conn.CredentialID)).Delete(); err != nil {
+			return err
+		}
+		item.CredentialIDs = append(item.CredentialIDs, *conn.CredentialID)
+	}
+
+	var ids []uint
+	m := tx.Metadata
+	if err := m.Where(m.ConnectionID.Eq(conn.ID)).Pluck(m.ID, &ids); err != nil {
+		return err
+	}
+	if _, err := m.Where(m.ConnectionID.Eq(conn.ID)).Delete(); err != nil {
+		return err
+	}
+	item.MetadataIDs = append(item.MetadataIDs, ids...)
+
+	ids = nil
+	a := tx.SecurityAudit
+	if err := a.Where(a.ConnectionID.Eq(conn.ID)).Pluck(a.ID, &ids); err != nil {
+		return err
+	}
+	if _, err := a.Where(a.ConnectionID.Eq(conn.ID)).Delete(); err != nil {
+		return err
+	}
+	item.SecurityAuditIDs = append(item.SecurityAuditIDs, ids...)
+
+	ids = nil
+	t := tx.Tunnel
+	if err := t.Where(t.ConnectionID.Eq(conn.ID)).Pluck(t.ID, &ids); err != nil {
+		return err
+	}
+	if _, err := t.Where(t.ConnectionID.Eq(conn.ID)).Delete(); err != nil {
+		return err
+	}
+	item.TunnelIDs = append(item.TunnelIDs, ids...)
+
+	if _, err := tx.Connection.Where(tx.Connection.ID.Eq(conn.ID)).Delete(); err != nil {
+		return err
+	}
+	item.ConnectionIDs = append(item.ConnectionIDs, conn.ID)
+	return nil
+}
+
+func trashCredential(tx *query.Query, id uint) error {
+	t := tx.Credential
+	cred, err := t.Where(t.ID.Eq(id)).First()
+	if err != nil {
+		return err
+	}
+	if _, err = t.Where(t.ID.Eq(id)).Delete(); err != nil {
+		return err
+	}
+	return tx.TrashItem.Create(&model.TrashItem{
+		Kind:          enums.TrashKindCredential,
+		Name:          cred.Label,
+		CredentialIDs: []uint{id},
+	})
+}
+
+// trashPrivateCredential soft deletes the private credential a connection or template
+// stopped using, as an item of its own named after the owner. Restoring it brings it
+// back as a common credential, the owner may have moved on to another one.
+func trashPrivateCredential(tx *query.Query, id uint, owner string) error {
+	if _, err := tx.Credential.Where(tx.Credential.ID.Eq(id)).Delete(); err != nil {
+		return err
+	}
+	return tx.TrashItem.Create(&model.TrashItem{
+		Kind:          enums.TrashKindCredential,
+		Name:          fmt.Sprintf("%s credential", owner),
+		CredentialIDs: []uint{id},
+	})
+}
+
+// restoreGroups restores the groups in the order they were deleted, parents first.
+// Only the topmost group gets a new position, its subgroups keep theirs.
+func restoreGroups(tx *query.Query, ids []uint, result *types.TrashRestoreResult) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	t := tx.Group
+	groups, err := t.Unscoped().Where(t.ID.In(ids...)).Find()
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(groups, func(a, b *model.Group) int {
+		return slices.Index(ids, a.ID) - slices.Index(ids, b.ID)
+	})
+
+	restored := make(map[uint]bool, len(groups))
+	for _, group := range groups {
+		withParent := group.ParentID != nil && restored[*group.ParentID]
+		if group.ParentID != nil && !withParent {
+			exists, err := staticGroupExists(tx, *group.ParentID)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				group.ParentID = nil
+				result.Ungrouped = append(result.Ungrouped, group.Name)
+			}
+		}
+		name, err := restoredName(group.Name, func(candidate string) (int64, error) {
+			return t.Where(groupParentIs(tx, group.ParentID), t.Name.Eq(candidate)).Count()
+		})
+		if err != nil {
+			return err
+		}
+		if name != group.Name {
+			result.Renamed = append(result.Renamed, &types.TrashRename{Kind: enums.TrashKindGroup, ID: group.ID, From: group.Name, To: name})
+			group.Name = name
+		}
+		if !withParent {
+			if group.SortOrder, err = nextGroupOrder(tx, group.ParentID); err != nil {
+				return err
+			}
+		}
+		if _, err = t.Unscoped().Where(t.ID.Eq(group.ID)).Select(t.Name, t.ParentID, t.SortOrder, t.DeletedAt).
+			Updates(&model.Group{Name: group.Name, ParentID: group.ParentID, SortOrder: group.SortOrder}); err != nil {
+			return err
+		}
+		restored[group.ID] = true
+	}
+	return nil
+}
+
+func restoreCredentials(tx *query.Query, ids []uint, result *types.TrashRestoreResult) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	t := tx.Credential
+	credentials, err := t.Unscoped().Where(t.ID.In(ids...)).Find()
+	if err != nil {
+		return err
+	}
+	for _, cred := range credentials {
+		label, err := restoredName(cred.Label, func(candidate string) (int64, error) {
+			return t.Where(t.Label.Eq(candidate)).Count()
+		})
+		if err != nil {
+			return err
+		}
+		if label != cred.Label {
+			result.Renamed = append(result.Renamed, &types.TrashRename{Kind: enums.TrashKindCredential, ID: cred.ID, From: cred.Label, To: label})
+		}
+		if _, err = t.Unscoped().Where(t.ID.Eq(cred.ID)).UpdateSimple(t.Label.Value(label), t.DeletedAt.Value(gorm.DeletedAt{})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreConnections puts the connections back at the end of their groups, keeping
+// their relative order.
+func restoreConnections(tx *query.Query, ids []uint, result *types.TrashRestoreResult) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	t := tx.Connection
+	conns, err := t.Unscoped().Where(t.ID.In(ids...)).Order(t.SortOrder, t.ID).Find()
+	if err != nil {
+		return err
+	}
+	for _, conn := range conns {
+		if conn.GroupID != nil {
+			exists, err := staticGroupExists(tx, *conn.GroupID)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				conn.GroupID = nil
+				result.Ungrouped = append(result.Ungrouped, conn.Label)
+			}
+		}
+		label, err := restoredName(conn.Label, func(candidate string) (int64, error) {
+			return t.Where(t.Label.Eq(candidate)).Count()
+		})
+		if err != nil {
+			return err
+		}
+		if label != conn.Label {
+			result.Renamed = append(result.Renamed, &types.TrashRename{Kind: enums.TrashKindConnection, ID: conn.ID, From: conn.Label, To: label})
+		}
+		order, err := nextConnectionOrder(tx, conn.GroupID)
+		if err != nil {
+			return err
+		}
+		if _, err = t.Unscoped().Where(t.ID.Eq(conn.ID)).Select(t.Label, t.GroupID, t.SortOrder, t.DeletedAt).
+			Updates(&model.Connection{Label: label, GroupID: conn.GroupID, SortOrder: order}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoredName keeps name when it is free, otherwise it adds the restored suffix.
+func restoredName(name string, count func(candidate string) (int64, error)) (string, error) {
+	taken, err := count(name)
+	if err != nil || taken == 0 {
+		return name, err
+	}
+	return uniqueName(name, restoredSuffix, count)
+}
+
+// staticGroupExists reports whether a live group that can hold connections and
+// subgroups has the ID.
+func staticGroupExists(tx *query.Query, id uint) (bool, error) {
+	count, err := tx.Group.Where(tx.Group.ID.Eq(id), tx.Group.Rule.Eq("")).Count()
+	return count > 0, err
+}
+
+// purgeTrashItem permanently deletes the rows of the item that are still deleted.
+func purgeTrashItem(tx *query.Query, item *model.TrashItem) error {
+	if len(item.ConnectionIDs) > 0 {
+		t := tx.Connection
+		if _, err := t.Unscoped().Where(t.ID.In(item.ConnectionIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	if len(item.CredentialIDs) > 0 {
+		t := tx.Credential
+		if _, err := t.Unscoped().Where(t.ID.In(item.CredentialIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	if len(item.GroupIDs) > 0 {
+		t := tx.Group
+		if _, err := t.Unscoped().Where(t.ID.In(item.GroupIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	if len(item.MetadataIDs) > 0 {
+		t := tx.Metadata
+		if _, err := t.Unscoped().Where(t.ID.In(item.MetadataIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	if len(item.SecurityAuditIDs) > 0 {
+		t := tx.SecurityAudit
+		if _, err := t.Unscoped().Where(t.ID.In(item.SecurityAuditIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	if len(item.TunnelIDs) > 0 {
+		t := tx.Tunnel
+		if _, err := t.Unscoped().Where(t.ID.In(item.TunnelIDs...), t.DeletedAt.IsNotNull()).Delete(); err != nil {
+			return err
+		}
+	}
+	_, err := tx.TrashItem.Where(tx.TrashItem.ID.Eq(item.ID)).Delete()
+	return err
+}
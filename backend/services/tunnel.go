@@ -0,0 +1,450 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/enums"
+	"github.com/Q191/GTerm/backend/initialize"
+	commonssh "github.com/Q191/GTerm/backend/pkg/ssh"
+	"github.com/Q191/GTerm/backend/pkg/tunnel"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gen/field"
+)
+
+const (
+	tunnelStatusEvent = "tunnel:status"
+	tunnelRestartMin  = 5 * time.Second
+	tunnelRestartMax  = time.Minute
+)
+
+var (
+	TunnelSrvSet     = wire.NewSet(wire.Struct(new(TunnelSrv), "*"))
+	TunnelRunnersSet = wire.NewSet(wire.Struct(new(TunnelRunners), "*"))
+)
+
+// TunnelSrv runs port forwards over saved SSH connections. Every tunnel uses its own
+// SSH client so stopping one never affects terminals or other tunnels. Tunnels started
+// from a saved profile are supervised and restarted after SSH failures.
+type TunnelSrv struct {
+	Logger        initialize.Logger
+	Query         *query.Query
+	ConnectionSrv *ConnectionSrv
+	AppContext    *initialize.AppContext
+	AuditLogSrv   *AuditLogSrv
+	Runners       *TunnelRunners
+	tunnels       map[string]*tunnel.Tunnel `wire:"-"`
+	mutex         sync.RWMutex              `wire:"-"`
+}
+
+// TunnelRunners tracks the supervised profile tunnels. It is shared with the
+// services that trash connections so that their tunnels stop with them.
+type TunnelRunners struct {
+	runners map[uint]chan struct{} `wire:"-"`
+	mutex   sync.Mutex             `wire:"-"`
+}
+
+func (s *TunnelSrv) StartLocalForward(connID uint, bind, target string) *resp.Resp {
+	s.Logger.Info("Starting local forward, connID: %d, bind: %s, target: %s", connID, bind, target)
+	return s.startAdHoc(connID, enums.TunnelTypeLocal, bind, target, nil)
+}
+
+func (s *TunnelSrv) StartRemoteForward(connID uint, bind, target string) *resp.Resp {
+	s.Logger.Info("Starting remote forward, connID: %d, bind: %s, target: %s", connID, bind, target)
+	return s.startAdHoc(connID, enums.TunnelTypeRemote, bind, target, nil)
+}
+
+func (s *TunnelSrv) StartDynamicForward(connID uint, bind string, opts *types.DynamicForwardOptions) *resp.Resp {
+	s.Logger.Info("Starting dynamic forward, connID: %d, bind: %s", connID, bind)
+	return s.startAdHoc(connID, enums.TunnelTypeDynamic, bind, "", opts)
+}
+
+func (s *TunnelSrv) ListTunnels() *resp.Resp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	tunnels := make([]*types.TunnelStatus, 0, len(s.tunnels))
+	for _, t := range s.tunnels {
+		tunnels = append(tunnels, t.Snapshot())
+	}
+	sort.Slice(tunnels, func(i, j int) bool {
+		return tunnels[i].StartedAt.Before(tunnels[j].StartedAt)
+	})
+	return resp.OkWithData(tunnels)
+}
+
+func (s *TunnelSrv) FindTunnelByID(id string) *resp.Resp {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	t, ok := s.tunnels[id]
+	if !ok {
+		return resp.FailWithMsg("tunnel not found")
+	}
+	return resp.OkWithData(t.Snapshot())
+}
+
+func (s *TunnelSrv) StopTunnel(id string) *resp.Resp {
+	s.mutex.Lock()
+	t, ok := s.tunnels[id]
+	delete(s.tunnels, id)
+	s.mutex.Unlock()
+	if !ok {
+		return resp.FailWithMsg("tunnel not found")
+	}
+	t.Stop()
+	return resp.OkWithCode(messages.TunnelStopSuccess)
+}
+
+func (s *TunnelSrv) CreateTunnel(profile *model.Tunnel) *resp.Resp {
+	if err := validateTunnel(profile); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if r := requireUnlocked(); profile.Password != "" && r != nil {
+		return r
+	}
+	t := s.Query.Tunnel
+	if err := t.Create(profile); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.CreateSuccess)
+}
+
+// UpdateTunnel refuses to change a profile while it runs, as the running tunnel
+// would keep its old settings. An empty password keeps the saved one unless the
+// username is cleared as well.
+func (s *TunnelSrv) UpdateTunnel(profile *model.Tunnel) *resp.Resp {
+	if err := validateTunnel(profile); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if s.Runners.running(profile.ID) {
+		return resp.FailWithCode(messages.TunnelRunning)
+	}
+	if r := requireUnlocked(); profile.Password != "" && r != nil {
+		return r
+	}
+	t := s.Query.Tunnel
+	columns := []field.Expr{t.Name, t.ConnectionID, t.Type, t.Bind, t.Target, t.RemoteDNS, t.AutoStart, t.Username}
+	if profile.Username == "" {
+		profile.Password, profile.PasswordCiphertext, profile.PasswordSalt = "", "", ""
+	}
+	if profile.Password != "" || profile.Username == "" {
+		columns = append(columns, t.PasswordCiphertext, t.PasswordSalt)
+	}
+	if _, err := t.Where(t.ID.Eq(profile.ID)).Select(columns...).Updates(profile); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.UpdateSuccess)
+}
+
+func (s *TunnelSrv) DeleteTunnel(id uint) *resp.Resp {
+	s.Runners.stop(id)
+	t := s.Query.Tunnel
+	if _, err := t.Where(t.ID.Eq(id)).Unscoped().Delete(); err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithCode(messages.DeleteSuccess)
+}
+
+func (s *TunnelSrv) ListTunnelProfiles() *resp.Resp {
+	t := s.Query.Tunnel
+	profiles, err := t.Find()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(profiles)
+}
+
+// StartTunnelProfile starts a saved tunnel under supervision. The first attempt is
+// made synchronously so that the caller sees bind or host key errors.
+func (s *TunnelSrv) StartTunnelProfile(id uint) *resp.Resp {
+	t := s.Query.Tunnel
+	profile, err := t.Where(t.ID.Eq(id)).First()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	stop, ok := s.Runners.claim(id)
+	if !ok {
+		return resp.FailWithMsg("tunnel is already running")
+	}
+
+	tun, err := s.openProfile(profile)
+	if err != nil {
+		s.Runners.release(id, stop)
+		s.Logger.Error("Failed to start tunnel %s: %v", profile.Name, err)
+		if r := hostKeyConfirmation(err); r != nil {
+			return r
+		}
+		return resp.FailWithMsg(err.Error())
+	}
+	s.supervise(profile, tun, stop)
+	return resp.OkWithCodeAndData(messages.TunnelStartSuccess, tun.Snapshot())
+}
+
+func (s *TunnelSrv) StopTunnelProfile(id uint) *resp.Resp {
+	if !s.Runners.stop(id) {
+		return resp.FailWithMsg("tunnel is not running")
+	}
+	return resp.OkWithCode(messages.TunnelStopSuccess)
+}
+
+// AutoStart brings up every saved tunnel flagged for auto-start. Tunnels that fail
+// to start are retried in the background like tunnels that fail later on.
+func (s *TunnelSrv) AutoStart() {
+	t := s.Query.Tunnel
+	profiles, err := t.Where(t.AutoStart.Is(true)).Find()
+	if err != nil {
+		s.Logger.Error("Failed to load auto-start tunnels: %v", err)
+		return
+	}
+	s.Logger.Info("Auto-starting %d tunnels", len(profiles))
+	for _, profile := range profiles {
+		stop, ok := s.Runners.claim(profile.ID)
+		if !ok {
+			continue
+		}
+		tun, err := s.openProfile(profile)
+		if err != nil {
+			s.Logger.Error("Failed to auto-start tunnel %s: %v", profile.Name, err)
+			if hostKeyConfirmation(err) != nil {
+				s.Runners.release(profile.ID, stop)
+				s.emitProfile(profile, enums.TunnelStatusFailed, err)
+				continue
+			}
+		}
+		s.supervise(profile, tun, stop)
+	}
+}
+
+func (s *TunnelSrv) startAdHoc(connID uint, tunnelType enums.TunnelType, bind, target string, opts *types.DynamicForwardOptions) *resp.Resp {
+	t, err := s.open(connID, tunnelType, bind, target, opts)
+	if err != nil {
+		s.Logger.Error("Failed to start %s forward: %v", tunnelType, err)
+		if r := hostKeyConfirmation(err); r != nil {
+			return r
+		}
+		return resp.FailWithMsg(err.Error())
+	}
+	s.register(t)
+	return resp.OkWithCodeAndData(messages.TunnelStartSuccess, t.Snapshot())
+}
+
+func (s *TunnelSrv) open(connID uint, tunnelType enums.TunnelType, bind, target string, opts *types.DynamicForwardOptions) (*tunnel.Tunnel, error) {
+	conn, err := s.ConnectionSrv.FindByID(connID)
+	if err != nil {
+		return nil, err
+	}
+	if conn.ConnProtocol != enums.SSH {
+		return nil, errors.New("port forwarding requires an SSH connection")
+	}
+	conf, err := s.ConnectionSrv.sshConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := commonssh.NewSSHClient(conf, s.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var t *tunnel.Tunnel
+	switch tunnelType {
+	case enums.TunnelTypeLocal:
+		t, err = tunnel.NewLocal(client, connID, bind, target, s.Logger)
+	case enums.TunnelTypeRemote:
+		t, err = tunnel.NewRemote(client, connID, bind, target, s.Logger)
+	case enums.TunnelTypeDynamic:
+		t, err = tunnel.NewDynamic(client, connID, bind, opts, s.Logger)
+	default:
+		err = fmt.Errorf("unsupported tunnel type: %s", tunnelType)
+	}
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *TunnelSrv) openProfile(profile *model.Tunnel) (*tunnel.Tunnel, error) {
+	if err := profile.Decrypt(); err != nil {
+		return nil, err
+	}
+	t, err := s.open(profile.ConnectionID, profile.Type, profile.Bind, profile.Target, &types.DynamicForwardOptions{
+		Username:  profile.Username,
+		Password:  profile.Password,
+		RemoteDNS: profile.RemoteDNS,
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.SetProfileID(profile.ID)
+	return t, nil
+}
+
+// register tracks a running tunnel and reports its status when it starts and stops.
+func (s *TunnelSrv) register(t *tunnel.Tunnel) {
+	s.mutex.Lock()
+	if s.tunnels == nil {
+		s.tunnels = make(map[string]*tunnel.Tunnel)
+	}
+	s.tunnels[t.ID()] = t
+	s.mutex.Unlock()
+
+	status := t.Snapshot()
+	s.AuditLogSrv.record(&model.AuditLog{
+		Action:       enums.AuditActionTunnelStart,
+		ConnectionID: status.ConnectionID,
+		Target:       status.Bind,
+		Detail:       strings.TrimSpace(fmt.Sprintf("%s %s", status.Type, status.Target)),
+	})
+	s.emit(status)
+	go func() {
+		<-t.Done()
+		s.emit(t.Snapshot())
+	}()
+}
+
+func (s *TunnelSrv) unregister(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.tunnels, id)
+}
+
+// supervise keeps a profile tunnel running until stop is closed. t may be nil when
+// the first attempt already failed.
+func (s *TunnelSrv) supervise(profile *model.Tunnel, t *tunnel.Tunnel, stop chan struct{}) {
+	go func() {
+		defer s.Runners.release(profile.ID, stop)
+
+		backoff := tunnelRestartMin
+		for {
+			if t != nil {
+				s.register(t)
+				select {
+				case <-t.Done():
+				case <-stop:
+					s.unregister(t.ID())
+					t.Stop()
+					return
+				}
+				s.unregister(t.ID())
+				if t.Err() == nil {
+					// stopped through StopTunnel
+					return
+				}
+				backoff = tunnelRestartMin
+			}
+
+			s.Logger.Info("Restarting tunnel %s in %s", profile.Name, backoff)
+			s.emitProfile(profile, enums.TunnelStatusRestarting, nil)
+			select {
+			case <-stop:
+				s.emitProfile(profile, enums.TunnelStatusStopped, nil)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, tunnelRestartMax)
+
+			var err error
+			if t, err = s.openProfile(profile); err != nil {
+				s.Logger.Error("Failed to restart tunnel %s: %v", profile.Name, err)
+				s.emitProfile(profile, enums.TunnelStatusFailed, err)
+				if hostKeyConfirmation(err) != nil {
+					// the host key has to be confirmed by the user first
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (s *TunnelSrv) emit(status *types.TunnelStatus) {
+	runtime.EventsEmit(s.AppContext.Context(), tunnelStatusEvent, status)
+}
+
+func (s *TunnelSrv) emitProfile(profile *model.Tunnel, status enums.TunnelStatus, err error) {
+	event := &types.TunnelStatus{
+		ProfileID:    profile.ID,
+		ConnectionID: profile.ConnectionID,
+		Type:         profile.Type,
+		Bind:         profile.Bind,
+		Target:       profile.Target,
+		Status:       status,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.emit(event)
+}
+
+func validateTunnel(profile *model.Tunnel) error {
+	if !slices.Contains(enums.TunnelTypeEnums, profile.Type) {
+		return fmt.Errorf("unsupported tunnel type: %s", profile.Type)
+	}
+	if profile.Bind == "" {
+		return errors.New("bind address is required")
+	}
+	if profile.Type != enums.TunnelTypeDynamic && profile.Target == "" {
+		return errors.New("target address is required")
+	}
+	return nil
+}
+
+// claim reserves the runner of a profile before its first attempt, so a profile
+// started twice at once only runs once. It reports false when the profile already
+// runs.
+func (r *TunnelRunners) claim(id uint) (chan struct{}, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, running := r.runners[id]; running {
+		return nil, false
+	}
+	if r.runners == nil {
+		r.runners = make(map[uint]chan struct{})
+	}
+	stop := make(chan struct{})
+	r.runners[id] = stop
+	return stop, true
+}
+
+// release gives up a runner claimed with stop, unless it was stopped meanwhile.
+func (r *TunnelRunners) release(id uint, stop chan struct{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.runners[id] == stop {
+		delete(r.runners, id)
+	}
+}
+
+func (r *TunnelRunners) running(id uint) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, ok := r.runners[id]
+	return ok
+}
+
+// stop stops the supervised tunnels of the profiles and reports whether any of them
+// was running.
+func (r *TunnelRunners) stop(ids ...uint) bool {
+	r.mutex.Lock()
+	var stops []chan struct{}
+	for _, id := range ids {
+		if stop, ok := r.runners[id]; ok {
+			stops = append(stops, stop)
+			delete(r.runners, id)
+		}
+	}
+	r.mutex.Unlock()
+	for _, stop := range stops {
+		close(stop)
+	}
+	return len(stops) > 0
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Q191/GTerm/backend/consts/messages"
+	"github.com/Q191/GTerm/backend/dal/model"
+	"github.com/Q191/GTerm/backend/dal/query"
+	"github.com/Q191/GTerm/backend/initialize"
+	"github.com/Q191/GTerm/backend/types"
+	"github.com/Q191/GTerm/backend/utils/encrypt"
+	"github.com/Q191/GTerm/backend/utils/resp"
+	"github.com/google/wire"
+	"gorm.io/gorm"
+)
+
+const minMasterPasswordLength = 8
+
+var VaultSrvSet = wire.NewSet(wire.Struct(new(VaultSrv), "*"))
+
+// VaultSrv manages the optional master password. With the vault enabled every
+// credential is encrypted with a random data key that is only held in memory while
+// the vault is unlocked.
+type VaultSrv struct {
+	Logger initialize.Logger
+	Query  *query.Query
+}
+
+// Load puts the encryption into the locked state when a vault has been set up. It
+// is called once at startup.
+func (s *VaultSrv) Load() {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		s.Logger.Error("Failed to initialize credential encryption: %v", err)
+		return
+	}
+	if _, err = s.vault(); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Logger.Error("Failed to load vault: %v", err)
+		}
+		return
+	}
+	cred.UseVault(nil)
+	s.Logger.Info("Master password vault is enabled and locked")
+}
+
+func (s *VaultSrv) VaultStatus() *resp.Resp {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	return resp.OkWithData(&types.VaultStatus{
+		Enabled: cred.VaultEnabled(),
+		Locked:  cred.Locked(),
+	})
+}
+
+// EnableVault sets up the master password and re-encrypts every credential with a
+// new data key.
+func (s *VaultSrv) EnableVault(password string) *resp.Resp {
+	if len(password) < minMasterPasswordLength {
+		return resp.FailWithCode(messages.MasterPasswordTooShort)
+	}
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if cred.VaultEnabled() {
+		return resp.FailWithCode(messages.VaultAlreadyEnabled)
+	}
+
+	key, dataKey, err := encrypt.NewVaultKey(password)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	vault := newVault(key)
+	if err = s.rekey(cred, func() { cred.UseVault(dataKey) }, func(tx *query.Query) error {
+		return tx.Vault.Create(vault)
+	}); err != nil {
+		s.Logger.Error("Failed to enable vault: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Master password vault enabled")
+	return resp.OkWithCode(messages.VaultEnableSuccess)
+}
+
+func (s *VaultSrv) UnlockVault(password string) *resp.Resp {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	dataKey, r := s.unwrap(password)
+	if r != nil {
+		return r
+	}
+	cred.UseVault(dataKey)
+	s.Logger.Info("Master password vault unlocked")
+	return resp.OkWithCode(messages.VaultUnlockSuccess)
+}
+
+func (s *VaultSrv) LockVault() *resp.Resp {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if !cred.VaultEnabled() {
+		return resp.FailWithCode(messages.VaultNotEnabled)
+	}
+	cred.Lock()
+	s.Logger.Info("Master password vault locked")
+	return resp.OkWithCode(messages.VaultLockSuccess)
+}
+
+// ChangeMasterPassword replaces the data key as well, so credentials copied while
+// encrypted with the old key cannot be opened with the old password. A locked vault
+// is locked again afterwards, whether or not the change succeeded.
+func (s *VaultSrv) ChangeMasterPassword(oldPassword, newPassword string) *resp.Resp {
+	if len(newPassword) < minMasterPasswordLength {
+		return resp.FailWithCode(messages.MasterPasswordTooShort)
+	}
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	oldKey, r := s.unwrap(oldPassword)
+	if r != nil {
+		return r
+	}
+	if cred.Locked() {
+		// unlocked for the re-encryption only
+		defer cred.Lock()
+	}
+	cred.UseVault(oldKey)
+
+	key, dataKey, err := encrypt.NewVaultKey(newPassword)
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if err = s.rekey(cred, func() { cred.UseVault(dataKey) }, func(tx *query.Query) error {
+		_, err := tx.Vault.Where(tx.Vault.ID.Gt(0)).Unscoped().Delete()
+		if err != nil {
+			return err
+		}
+		return tx.Vault.Create(newVault(key))
+	}); err != nil {
+		s.Logger.Error("Failed to change master password: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Master password changed")
+	return resp.OkWithCode(messages.MasterPasswordChangeSuccess)
+}
+
+// DisableVault removes the master password and re-encrypts every credential with
+// the machine ID derived key.
+func (s *VaultSrv) DisableVault(password string) *resp.Resp {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	dataKey, r := s.unwrap(password)
+	if r != nil {
+		return r
+	}
+	if cred.Locked() {
+		// stays locked when the vault cannot be disabled
+		defer cred.Lock()
+	}
+	cred.UseVault(dataKey)
+
+	if err = s.rekey(cred, cred.UseMachineKey, func(tx *query.Query) error {
+		_, err := tx.Vault.Where(tx.Vault.ID.Gt(0)).Unscoped().Delete()
+		return err
+	}); err != nil {
+		s.Logger.Error("Failed to disable vault: %v", err)
+		return resp.FailWithMsg(err.Error())
+	}
+	s.Logger.Info("Master password vault disabled")
+	return resp.OkWithCode(messages.VaultDisableSuccess)
+}
+
+func (s *VaultSrv) vault() (*model.Vault, error) {
+	return s.Query.Vault.First()
+}
+
+func (s *VaultSrv) unwrap(password string) ([]byte, *resp.Resp) {
+	vault, err := s.vault()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, resp.FailWithCode(messages.VaultNotEnabled)
+	}
+	if err != nil {
+		return nil, resp.FailWithMsg(err.Error())
+	}
+	key := &encrypt.VaultKey{
+		Salt:       vault.Salt,
+		Time:       vault.Time,
+		Memory:     vault.Memory,
+		Threads:    vault.Threads,
+		WrappedKey: vault.WrappedKey,
+	}
+	dataKey, err := key.Unwrap(password)
+	if errors.Is(err, encrypt.ErrInvalidMasterPassword) {
+		s.Logger.Warn("Invalid master password")
+		return nil, resp.FailWithCode(messages.InvalidMasterPassword)
+	}
+	if err != nil {
+		return nil, resp.FailWithMsg(err.Error())
+	}
+	return dataKey, nil
+}
+
+// rekey decrypts every credential and tunnel password with the current key, switches
+// to the new key and saves them again together with the vault change. The previous
+// key is restored if the transaction fails.
+func (s *VaultSrv) rekey(cred *encrypt.Credential, switchKey func(), persist func(tx *query.Query) error) error {
+	credentials, err := s.Query.Credential.Unscoped().Find()
+	if err != nil {
+		return err
+	}
+	for _, c := range credentials {
+		if err = c.Decrypt(); err != nil {
+			return err
+		}
+	}
+	tunnels, err := s.Query.Tunnel.Unscoped().Where(s.Query.Tunnel.PasswordCiphertext.Neq("")).Find()
+	if err != nil {
+		return err
+	}
+	for _, t := range tunnels {
+		if err = t.Decrypt(); err != nil {
+			return err
+		}
+	}
+
+	enabled := cred.VaultEnabled()
+	previous, err := s.currentKey(cred)
+	if err != nil {
+		return err
+	}
+	switchKey()
+
+	if err = s.Query.Transaction(func(tx *query.Query) error {
+		if err := persist(tx); err != nil {
+			return err
+		}
+		for _, c := range credentials {
+			if err := tx.Credential.Unscoped().Save(c); err != nil {
+				return err
+			}
+		}
+		for _, t := range tunnels {
+			if err := tx.Tunnel.Unscoped().Save(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		if enabled {
+			cred.UseVault(previous)
+		} else {
+			cred.UseMachineKey()
+		}
+		return err
+	}
+	s.Logger.Info("Re-encrypted %d credentials", len(credentials))
+	return nil
+}
+
+// currentKey returns the vault data key in use, or nil for the machine key.
+func (s *VaultSrv) currentKey(cred *encrypt.Credential) ([]byte, error) {
+	if !cred.VaultEnabled() {
+		return nil, nil
+	}
+	if cred.Locked() {
+		return nil, encrypt.ErrVaultLocked
+	}
+	return cred.DataKey(), nil
+}
+
+func newVault(key *encrypt.VaultKey) *model.Vault {
+	return &model.Vault{
+		Salt:       key.Salt,
+		Time:       key.Time,
+		Memory:     key.Memory,
+		Threads:    key.Threads,
+		WrappedKey: key.WrappedKey,
+	}
+}
+
+// requireUnlocked fails fast with a clear message while the vault is locked.
+func requireUnlocked() *resp.Resp {
+	cred, err := encrypt.NewCredential()
+	if err != nil {
+		return resp.FailWithMsg(err.Error())
+	}
+	if cred.Locked() {
+		return resp.FailWithCode(messages.VaultLocked)
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ var WebsocketSrvSet = wire.NewSet(wire.Struct(new(WebsocketSrv), "*"))
 
 type WebsocketSrv struct {
 	TerminalSrv *TerminalSrv
+	HistorySrv  *HistorySrv
 	Logger      initialize.Logger
 }
 
@@ -67,6 +68,7 @@ var errorMappings = map[string]string{
 	"resource":             messages.ResourceExhausted,
 	"too many connections": messages.ResourceExhausted,
 	"connection limit":     messages.ResourceExhausted,
+	"vault is locked":      messages.VaultLocked,
 }
 
 func (s *WebsocketSrv) formatError(err error) *types.Message {
@@ -147,71 +149,71 @@ func (s *WebsocketSrv) TerminalHandle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.Logger.Info("WebSocket connection upgraded successfully, hostId: %d, remote_addr: %s", hostID, r.RemoteAddr)
+	s.HistorySrv.start(ws, uint(hostID))
+	// a fallback, every path closes the session with its own reason first
+	defer s.HistorySrv.finish(ws, messages.ConnectionClosed)
 
 	// 建立SSH连接
 	s.Logger.Info("Starting SSH connection, hostId: %d", hostID)
 	err = s.TerminalSrv.SSH(ws, uint(hostID))
 
 	var fingerprintErr *types.FingerprintError
-	if errors.As(err, &fingerprintErr) {
+	var changedErr *types.HostKeyChangedError
+	switch {
+	case errors.As(err, &fingerprintErr):
 		// 发送主机指纹确认消息
 		s.Logger.Info("Host fingerprint confirmation needed, host: %s, fingerprint: %s",
 			fingerprintErr.Host,
 			fingerprintErr.Fingerprint,
 		)
-		if err = ws.WriteJSON(&types.Message{
+		if !s.confirmHostKey(ws, &types.Message{
 			Type:        enums.TerminalTypeFingerprintConfirm,
 			Host:        fingerprintErr.Host,
 			Fingerprint: fingerprintErr.Fingerprint,
-		}); err != nil {
-			s.Logger.Error("Failed to send host fingerprint confirmation message: %v", err)
-			s.TerminalSrv.CloseSession(ws, messages.FailedToSendFingerprintMsg)
+		}) {
 			return
 		}
-		s.Logger.Info("Host fingerprint confirmation message sent, waiting for client confirmation")
 
-		// 等待客户端确认
-		_, data, err := ws.ReadMessage()
-		if err != nil {
-			s.Logger.Error("Failed to read host fingerprint confirmation response: %v", err)
-			s.TerminalSrv.CloseSession(ws, messages.FailedToReadFingerprint)
+		s.Logger.Info("Client accepted host fingerprint, adding to known_hosts")
+		// 添加主机指纹并重新连接
+		if err = s.TerminalSrv.AddFingerprint(uint(hostID), fingerprintErr.Host, fingerprintErr.Fingerprint); err != nil {
+			s.Logger.Error("Failed to add host fingerprint: %v", err)
+			s.handleError(ws, err)
+			s.TerminalSrv.CloseSession(ws, messages.FailedToAddFingerprint)
 			return
 		}
-		s.Logger.Info("Received client fingerprint confirmation response")
-
-		var fg types.Fingerprint
-		if err = json.Unmarshal(data, &fg); err != nil {
-			s.Logger.Error("Failed to parse host fingerprint confirmation response: %v, data: %s", err, string(data))
-			s.TerminalSrv.CloseSession(ws, messages.FailedToParseFingerprint)
+		s.Logger.Info("Host fingerprint added, retrying connection")
+		if !s.reconnect(ws, uint(hostID)) {
+			return
+		}
+	case errors.As(err, &changedErr):
+		// 主机密钥已变更, 需要用户明确确认替换
+		s.Logger.Warn("Host key changed, host: %s, old: %v, new: %s",
+			changedErr.Host,
+			changedErr.OldFingerprints,
+			changedErr.Fingerprint,
+		)
+		if !s.confirmHostKey(ws, &types.Message{
+			Type:            enums.TerminalTypeHostKeyChanged,
+			Host:            changedErr.Host,
+			Fingerprint:     changedErr.Fingerprint,
+			OldFingerprints: changedErr.OldFingerprints,
+		}) {
 			return
 		}
 
-		if fg.Type == enums.TerminalTypeFingerprintConfirm && fg.Accept {
-			s.Logger.Info("Client accepted host fingerprint, adding to known_hosts")
-			// 添加主机指纹并重新连接
-			if err = s.TerminalSrv.AddFingerprint(uint(hostID), fingerprintErr.Host, fingerprintErr.Fingerprint); err != nil {
-				s.Logger.Error("Failed to add host fingerprint: %v", err)
-				s.handleError(ws, err)
-				s.TerminalSrv.CloseSession(ws, messages.FailedToAddFingerprint)
-				return
-			}
-			s.Logger.Info("Host fingerprint added, retrying connection")
-
-			// 重新尝试连接
-			if err = s.TerminalSrv.SSH(ws, uint(hostID)); err != nil {
-				s.Logger.Error("Failed to reconnect SSH: %v", err)
-				s.handleError(ws, err)
-				s.TerminalSrv.CloseSession(ws, s.formatError(err).Message)
-				return
-			}
-			s.Logger.Info("SSH reconnection successful")
-		} else {
-			// 用户拒绝添加主机指纹
-			s.Logger.Info("Client rejected host fingerprint, closing connection")
-			s.TerminalSrv.CloseSession(ws, messages.UserRejectedFingerprint)
+		s.Logger.Info("Client accepted changed host key, replacing known_hosts entries")
+		if err = s.TerminalSrv.ReplaceFingerprint(uint(hostID), changedErr.Host, changedErr.Fingerprint); err != nil {
+			s.Logger.Error("Failed to replace host fingerprint: %v", err)
+			s.handleError(ws, err)
+			s.TerminalSrv.CloseSession(ws, messages.FailedToReplaceFingerprint)
 			return
 		}
-	} else if err != nil {
+		s.Logger.Info("Host fingerprint replaced, retrying connection")
+		if !s.reconnect(ws, uint(hostID)) {
+			return
+		}
+	case err != nil:
 		s.Logger.Error("SSH connection failed: %v", err)
 		s.handleError(ws, err)
 		s.TerminalSrv.CloseSession(ws, s.formatError(err).Message)
@@ -221,3 +223,54 @@ func (s *WebsocketSrv) TerminalHandle(w http.ResponseWriter, r *http.Request) {
 	s.Logger.Info("Terminal session ended, closing WebSocket connection")
 	s.TerminalSrv.CloseSession(ws, messages.SessionEnded)
 }
+
+// confirmHostKey sends a host key prompt and waits for the client's decision. The
+// session is closed with a matching reason unless the client accepted.
+func (s *WebsocketSrv) confirmHostKey(ws *websocket.Conn, prompt *types.Message) bool {
+	if err := ws.WriteJSON(prompt); err != nil {
+		s.Logger.Error("Failed to send host key confirmation message: %v", err)
+		s.TerminalSrv.CloseSession(ws, messages.FailedToSendFingerprintMsg)
+		return false
+	}
+	s.Logger.Info("Host key confirmation message sent, waiting for client confirmation")
+
+	// 等待客户端确认
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		s.Logger.Error("Failed to read host key confirmation response: %v", err)
+		s.TerminalSrv.CloseSession(ws, messages.FailedToReadFingerprint)
+		return false
+	}
+	s.Logger.Info("Received client host key confirmation response")
+
+	var fg types.Fingerprint
+	if err = json.Unmarshal(data, &fg); err != nil {
+		s.Logger.Error("Failed to parse host key confirmation response: %v, data: %s", err, string(data))
+		s.TerminalSrv.CloseSession(ws, messages.FailedToParseFingerprint)
+		return false
+	}
+
+	if fg.Type != prompt.Type || !fg.Accept {
+		// 用户拒绝主机指纹
+		s.Logger.Info("Client rejected host key, closing connection")
+		if prompt.Type == enums.TerminalTypeHostKeyChanged {
+			s.TerminalSrv.CloseSession(ws, messages.UserRejectedHostKeyChange)
+		} else {
+			s.TerminalSrv.CloseSession(ws, messages.UserRejectedFingerprint)
+		}
+		return false
+	}
+	return true
+}
+
+func (s *WebsocketSrv) reconnect(ws *websocket.Conn, hostID uint) bool {
+	// 重新尝试连接
+	if err := s.TerminalSrv.SSH(ws, hostID); err != nil {
+		s.Logger.Error("Failed to reconnect SSH: %v", err)
+		s.handleError(ws, err)
+		s.TerminalSrv.CloseSession(ws, s.formatError(err).Message)
+		return false
+	}
+	s.Logger.Info("SSH reconnection successful")
+	return true
+}
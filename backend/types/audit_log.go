@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+)
+
+type AuditLogFilter struct {
+	Actions      []enums.AuditAction `json:"actions"`
+	ConnectionID uint                `json:"connectionId"`
+	CredentialID uint                `json:"credentialId"`
+	From         *time.Time          `json:"from"`
+	To           *time.Time          `json:"to"`
+	// Keyword matches the target and detail.
+	Keyword string `json:"keyword"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+}
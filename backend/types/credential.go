@@ -0,0 +1,40 @@
+package types
+
+type CredentialConnectionUsage struct {
+	ID    uint   `json:"id"`
+	Label string `json:"label"`
+	Host  string `json:"host"`
+	// Primary is false when the credential is only part of the fallback chain.
+	Primary bool `json:"primary"`
+	// Proxy is true when the credential authenticates with the proxy.
+	Proxy bool `json:"proxy"`
+	// Trashed connections count as users, restoring them needs the credential.
+	Trashed bool `json:"trashed"`
+}
+
+type CredentialGroupUsage struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// Primary is true when the group passes the credential on to its connections.
+	Primary bool `json:"primary"`
+	Proxy   bool `json:"proxy"`
+	Trashed bool `json:"trashed"`
+}
+
+type CredentialTemplateUsage struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Primary bool   `json:"primary"`
+	Proxy   bool   `json:"proxy"`
+}
+
+type CredentialUsage struct {
+	CredentialID uint                         `json:"credentialId"`
+	Connections  []*CredentialConnectionUsage `json:"connections"`
+	Groups       []*CredentialGroupUsage      `json:"groups"`
+	Templates    []*CredentialTemplateUsage   `json:"templates"`
+}
+
+func (u *CredentialUsage) InUse() bool {
+	return len(u.Connections) > 0 || len(u.Groups) > 0 || len(u.Templates) > 0
+}
@@ -14,6 +14,8 @@ type Message struct {
 	Code        string             `json:"code,omitempty"`
 	Host        string             `json:"host,omitempty"`
 	Fingerprint string             `json:"fingerprint,omitempty"`
+	// OldFingerprints lists the pinned keys when the host key has changed
+	OldFingerprints []string `json:"oldFingerprints,omitempty"`
 }
 
 type Fingerprint struct {
@@ -0,0 +1,28 @@
+package types
+
+import "github.com/Q191/GTerm/backend/enums"
+
+type RotationRequest struct {
+	CredentialID  uint   `json:"credentialId"`
+	ConnectionIDs []uint `json:"connectionIds"`
+	// NewPassword is generated when empty.
+	NewPassword    string `json:"newPassword"`
+	PasswordLength int    `json:"passwordLength"`
+}
+
+type RotationHostResult struct {
+	ConnectionID  uint                 `json:"connectionId"`
+	Label         string               `json:"label"`
+	Host          string               `json:"host"`
+	Vendor        string               `json:"vendor"`
+	Status        enums.RotationStatus `json:"status"`
+	Error         string               `json:"error"`
+	RollbackError string               `json:"rollbackError"`
+}
+
+type RotationReport struct {
+	CredentialID uint `json:"credentialId"`
+	// CredentialUpdated is true only when every host verified the new password.
+	CredentialUpdated bool                  `json:"credentialUpdated"`
+	Hosts             []*RotationHostResult `json:"hosts"`
+}
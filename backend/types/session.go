@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+type SessionStats struct {
+	SessionID    string    `json:"sessionId"`
+	ConnectionID uint      `json:"connectionId"`
+	Host         string    `json:"host"`
+	StartedAt    time.Time `json:"startedAt"`
+	// Uptime is in seconds, Latency in milliseconds and throughput in bytes per second.
+	Uptime        int64   `json:"uptime"`
+	Latency       int64   `json:"latency"`
+	BytesIn       int64   `json:"bytesIn"`
+	BytesOut      int64   `json:"bytesOut"`
+	ThroughputIn  float64 `json:"throughputIn"`
+	ThroughputOut float64 `json:"throughputOut"`
+}
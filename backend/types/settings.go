@@ -0,0 +1,10 @@
+package types
+
+import "github.com/Q191/GTerm/backend/enums"
+
+type SettingOrigin struct {
+	Source enums.SettingSource `json:"source"`
+	// GroupID and GroupName are set when the value is inherited from a group.
+	GroupID   uint   `json:"groupId,omitempty"`
+	GroupName string `json:"groupName,omitempty"`
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+)
+
+type SSHAlgorithms struct {
+	KeyExchanges        []string `json:"keyExchanges"`
+	Ciphers             []string `json:"ciphers"`
+	MACs                []string `json:"macs"`
+	HostKeyAlgorithms   []string `json:"hostKeyAlgorithms"`
+	PublicKeyAlgorithms []string `json:"publicKeyAlgorithms"`
+}
+
+type SupportedSSHAlgorithms struct {
+	// Supported lists every algorithm the client can negotiate, including legacy ones
+	// that are only offered when configured explicitly.
+	Supported *SSHAlgorithms `json:"supported"`
+	// Default lists the algorithms offered when a connection leaves the list empty.
+	Default *SSHAlgorithms `json:"default"`
+}
+
+// SSHExpectedAlgorithms are the algorithms a client is expected to negotiate with a
+// server, worked out from both preference lists without a key exchange.
+type SSHExpectedAlgorithms struct {
+	KeyExchange          string `json:"keyExchange"`
+	HostKeyAlgorithm     string `json:"hostKeyAlgorithm"`
+	CipherClientToServer string `json:"cipherClientToServer"`
+	CipherServerToClient string `json:"cipherServerToClient"`
+	MACClientToServer    string `json:"macClientToServer"`
+	MACServerToClient    string `json:"macServerToClient"`
+}
+
+type SSHServerProbe struct {
+	ServerVersion string                 `json:"serverVersion"`
+	Offered       *SSHAlgorithms         `json:"offered"`
+	Expected      *SSHExpectedAlgorithms `json:"expected"`
+}
+
+// SSHAuditFinding rates one offered algorithm. Expected marks the algorithms
+// expected to be negotiated.
+type SSHAuditFinding struct {
+	Category  string            `json:"category"`
+	Algorithm string            `json:"algorithm"`
+	Rating    enums.AuditRating `json:"rating"`
+	Reason    string            `json:"reason"`
+	Expected  bool              `json:"expected"`
+}
+
+type SSHTraceEvent struct {
+	Time    time.Time `json:"time"`
+	Elapsed int64     `json:"elapsed"`
+	Stage   string    `json:"stage"`
+	Message string    `json:"message"`
+}
+
+type SSHTrace struct {
+	ID           string           `json:"id"`
+	ConnectionID uint             `json:"connectionId"`
+	Host         string           `json:"host"`
+	StartedAt    time.Time        `json:"startedAt"`
+	FinishedAt   *time.Time       `json:"finishedAt"`
+	Success      bool             `json:"success"`
+	Error        string           `json:"error"`
+	Events       []*SSHTraceEvent `json:"events"`
+}
+
+type KnownHostEntry struct {
+	Line        int      `json:"line"`
+	Marker      string   `json:"marker"`
+	Hosts       []string `json:"hosts"`
+	Hashed      bool     `json:"hashed"`
+	KeyType     string   `json:"keyType"`
+	Fingerprint string   `json:"fingerprint"`
+	Comment     string   `json:"comment"`
+}
+
+type HostKeyChangedError struct {
+	Host            string   `json:"host"`
+	Fingerprint     string   `json:"fingerprint"`
+	OldFingerprints []string `json:"oldFingerprints"`
+}
+
+func (e *HostKeyChangedError) Error() string {
+	return fmt.Sprintf("host key changed for %s: %s", e.Host, e.Fingerprint)
+}
+
+type HostKeyScanResult struct {
+	ConnectionID uint   `json:"connectionId"`
+	Label        string `json:"label"`
+	Host         string `json:"host"`
+	Fingerprint  string `json:"fingerprint"`
+	Status       string `json:"status"`
+	Error        string `json:"error"`
+}
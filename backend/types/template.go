@@ -0,0 +1,18 @@
+package types
+
+type StampRequest struct {
+	TemplateID uint `json:"templateId"`
+	// Hosts holds whitespace separated host patterns such as 10.0.1.[1-40].
+	Hosts string `json:"hosts"`
+	// LabelPattern and GroupID override the template when set.
+	LabelPattern string `json:"labelPattern"`
+	GroupID      *uint  `json:"groupID"`
+}
+
+type StampedConnection struct {
+	ID    uint   `json:"id"`
+	Label string `json:"label"`
+	Host  string `json:"host"`
+	// Exists is set by the preview when the label is already taken.
+	Exists bool `json:"exists"`
+}
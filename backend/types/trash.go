@@ -0,0 +1,18 @@
+package types
+
+import "github.com/Q191/GTerm/backend/enums"
+
+// TrashRename is an item restored under a new name because its own was taken.
+type TrashRename struct {
+	Kind enums.TrashKind `json:"kind"`
+	ID   uint            `json:"id"`
+	From string          `json:"from"`
+	To   string          `json:"to"`
+}
+
+type TrashRestoreResult struct {
+	Renamed []*TrashRename `json:"renamed"`
+	// Ungrouped lists the connections and groups whose group was gone, they were put
+	// at the top level.
+	Ungrouped []string `json:"ungrouped"`
+}
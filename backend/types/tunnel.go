@@ -0,0 +1,45 @@
+package types
+
+import (
+	"time"
+
+	"github.com/Q191/GTerm/backend/enums"
+)
+
+type TunnelStatus struct {
+	ID                string             `json:"id"`
+	ProfileID         uint               `json:"profileId,omitempty"`
+	ConnectionID      uint               `json:"connectionId"`
+	Type              enums.TunnelType   `json:"type"`
+	Bind              string             `json:"bind"`
+	Target            string             `json:"target"`
+	Status            enums.TunnelStatus `json:"status"`
+	Error             string             `json:"error"`
+	StartedAt         time.Time          `json:"startedAt"`
+	ActiveConnections int64              `json:"activeConnections"`
+	TotalConnections  int64              `json:"totalConnections"`
+	// BytesIn is traffic received from the forwarded target, BytesOut traffic sent to it.
+	BytesIn  int64 `json:"bytesIn"`
+	BytesOut int64 `json:"bytesOut"`
+}
+
+type DynamicForwardOptions struct {
+	// Username and Password enable RFC 1929 authentication when Username is set.
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// RemoteDNS passes domain names to the SSH server instead of resolving them locally.
+	RemoteDNS bool `json:"remoteDNS"`
+}
+
+type ListeningPort struct {
+	Bind    string `json:"bind"`
+	Port    uint16 `json:"port"`
+	Process string `json:"process"`
+	PID     int    `json:"pid"`
+}
+
+type ListeningPortList struct {
+	ConnectionID uint             `json:"connectionId"`
+	Ports        []*ListeningPort `json:"ports"`
+	ScannedAt    time.Time        `json:"scannedAt"`
+}
@@ -0,0 +1,6 @@
+package types
+
+type VaultStatus struct {
+	Enabled bool `json:"enabled"`
+	Locked  bool `json:"locked"`
+}
@@ -25,10 +25,18 @@ var (
 	once       sync.Once
 	credential *Credential
 	initErr    error
+
+	ErrVaultLocked = errors.New("vault is locked")
 )
 
+// Credential encrypts secrets with a key derived from the machine ID, or with the
+// data key of the master password vault once one is set up.
 type Credential struct {
 	machineID []byte
+
+	mu           sync.RWMutex
+	vaultEnabled bool
+	dataKey      []byte
 }
 
 type Encrypted struct {
@@ -56,13 +64,73 @@ func NewCredential() (*Credential, error) {
 	return credential, initErr
 }
 
+// UseVault switches encryption to the vault data key. A nil key leaves the vault
+// locked, so every encryption and decryption fails with ErrVaultLocked.
+func (c *Credential) UseVault(dataKey []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vaultEnabled = true
+	c.dataKey = dataKey
+}
+
+// UseMachineKey switches encryption back to the machine ID derived key.
+func (c *Credential) UseMachineKey() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vaultEnabled = false
+	c.dataKey = nil
+}
+
+func (c *Credential) Lock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.dataKey)
+	c.dataKey = nil
+}
+
+func (c *Credential) VaultEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.vaultEnabled
+}
+
+func (c *Credential) Locked() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.vaultEnabled && c.dataKey == nil
+}
+
+// DataKey returns the vault data key, or nil while the vault is locked or disabled.
+func (c *Credential) DataKey() []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dataKey
+}
+
+func (c *Credential) secret() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.vaultEnabled {
+		return c.machineID, nil
+	}
+	if c.dataKey == nil {
+		return nil, ErrVaultLocked
+	}
+	return c.dataKey, nil
+}
+
 func (c *Credential) EncryptPassword(plaintext string) (*Encrypted, error) {
+	secret, err := c.secret()
+	if err != nil {
+		return nil, err
+	}
+
 	salt := make([]byte, saltLength)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		return nil, err
 	}
 
-	key := pbkdf2.Key(c.machineID, salt, iterationCount, keyLength, sha256.New)
+	key := pbkdf2.Key(secret, salt, iterationCount, keyLength, sha256.New)
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -88,12 +156,17 @@ func (c *Credential) EncryptPassword(plaintext string) (*Encrypted, error) {
 }
 
 func (c *Credential) DecryptPassword(ciphertext, salt string) (string, error) {
+	secret, err := c.secret()
+	if err != nil {
+		return "", err
+	}
+
 	decodeSalt, err := base64.StdEncoding.DecodeString(salt)
 	if err != nil {
 		return "", err
 	}
 
-	key := pbkdf2.Key(c.machineID, decodeSalt, iterationCount, keyLength, sha256.New)
+	key := pbkdf2.Key(secret, decodeSalt, iterationCount, keyLength, sha256.New)
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -0,0 +1,95 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters follow the second recommendation of RFC 9106 for memory
+// constrained environments.
+const (
+	Argon2Time    uint32 = 3
+	Argon2Memory  uint32 = 64 * 1024
+	Argon2Threads uint8  = 4
+)
+
+var ErrInvalidMasterPassword = errors.New("invalid master password")
+
+// VaultKey is a data key wrapped by a key derived from the master password.
+type VaultKey struct {
+	Salt       string
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	WrappedKey string
+}
+
+// NewVaultKey generates a random data key and wraps it with the master password.
+func NewVaultKey(password string) (*VaultKey, []byte, error) {
+	dataKey := make([]byte, keyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, nil, err
+	}
+
+	kek := argon2.IDKey([]byte(password), salt, Argon2Time, Argon2Memory, Argon2Threads, keyLength)
+	gcm, err := newGCM(kek)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+
+	return &VaultKey{
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Time:       Argon2Time,
+		Memory:     Argon2Memory,
+		Threads:    Argon2Threads,
+		WrappedKey: base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, dataKey, nil)),
+	}, dataKey, nil
+}
+
+// Unwrap returns the data key, or ErrInvalidMasterPassword if the password is wrong.
+func (v *VaultKey) Unwrap(password string) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(v.Salt)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(v.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	kek := argon2.IDKey([]byte(password), salt, v.Time, v.Memory, v.Threads, keyLength)
+	gcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, errors.New("insufficient wrapped key length")
+	}
+	dataKey, err := gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrInvalidMasterPassword
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}